@@ -74,13 +74,18 @@ func main() {
 		log.Fatal(sdl.GetError())
 	}
 
+	if _, ok := sdl.JoystickName(sdl.NumJoysticks()); ok {
+		log.Fatal("JoystickName accepted an out-of-range index")
+	}
+
 	if sdl.NumJoysticks() > 0 {
 		// Open joystick
 		joy = sdl.JoystickOpen(0)
 
 		if joy != nil {
 			println("Opened Joystick 0")
-			println("Name: ", sdl.JoystickName(0))
+			name, _ := sdl.JoystickName(0)
+			println("Name: ", name)
 			println("Number of Axes: ", joy.NumAxes())
 			println("Number of Buttons: ", joy.NumButtons())
 			println("Number of Balls: ", joy.NumBalls())
@@ -223,7 +228,7 @@ func main() {
 				println("Joystick Button Event ->", e.Button)
 				println("State of button", e.Button, "->", joy.GetButton(int(e.Button)))
 
-			case sdl.ResizeEvent:
+			case sdl.VideoResizeEvent:
 				println("resize screen ", e.W, e.H)
 
 				screen = sdl.SetVideoMode(int(e.W), int(e.H), 32, sdl.RESIZABLE)
@@ -0,0 +1,106 @@
+package sdl
+
+// Counts how often each value of the red, green and blue channels occurs
+// in the pixels of the surface. Fully transparent pixels (with zero alpha,
+// or matching the colorkey) are skipped, since their color isn't visible.
+// Use HistogramAll to count them too. All counts are zero if the surface
+// can't be locked.
+func (s *Surface) Histogram() (r, g, b [256]uint64) {
+	return s.histogram(true)
+}
+
+// Same as Histogram, but counts all pixels, including transparent ones.
+func (s *Surface) HistogramAll() (r, g, b [256]uint64) {
+	return s.histogram(false)
+}
+
+func (s *Surface) histogram(skipTransparent bool) (r, g, b [256]uint64) {
+	f := s.Format
+
+	if s.Lock() != 0 {
+		return
+	}
+	for y := 0; y < int(s.H); y++ {
+		for x := 0; x < int(s.W); x++ {
+			pixel := s.getPixel(x, y)
+			if skipTransparent && s.transparent(pixel) {
+				continue
+			}
+
+			pr, pg, pb, _ := decodeRGBA(f, pixel)
+			r[pr]++
+			g[pg]++
+			b[pb]++
+		}
+	}
+	s.Unlock()
+
+	return
+}
+
+// Ways of choosing the dominant color, see DominantColor.
+const (
+	DominantMode = iota // The most common color
+	DominantMean        // The average color
+)
+
+// Returns the dominant color of the visible (not fully transparent) pixels
+// of the surface, for example to derive an accent color from a thumbnail.
+//
+// With DominantMode, similar colors are grouped (by the 4 high bits of
+// each channel) and the average of the largest group is returned, so that
+// slight variations, as in photos, don't split up a color. With DominantMean,
+// the average of all visible pixels is returned. If no pixel is visible,
+// or the surface can't be locked, the result is black.
+func (s *Surface) DominantColor(method int) Color {
+	f := s.Format
+
+	var sumR, sumG, sumB, count [4096]uint64
+	var total [3]uint64
+	var n uint64
+
+	if s.Lock() != 0 {
+		return Color{}
+	}
+	for y := 0; y < int(s.H); y++ {
+		for x := 0; x < int(s.W); x++ {
+			pixel := s.getPixel(x, y)
+			if s.transparent(pixel) {
+				continue
+			}
+
+			r, g, b, _ := decodeRGBA(f, pixel)
+			if method == DominantMean {
+				total[0] += uint64(r)
+				total[1] += uint64(g)
+				total[2] += uint64(b)
+				n++
+				continue
+			}
+
+			i := int(r>>4)<<8 | int(g>>4)<<4 | int(b>>4)
+			sumR[i] += uint64(r)
+			sumG[i] += uint64(g)
+			sumB[i] += uint64(b)
+			count[i]++
+		}
+	}
+	s.Unlock()
+
+	if method != DominantMean {
+		best := 0
+		for i := range count {
+			if count[i] > count[best] {
+				best = i
+			}
+		}
+		total = [3]uint64{sumR[best], sumG[best], sumB[best]}
+		n = count[best]
+	}
+
+	if n == 0 {
+		return Color{}
+	}
+
+	return Color{R: uint8(total[0] / n), G: uint8(total[1] / n), B: uint8(total[2] / n)}
+}
@@ -0,0 +1,38 @@
+package sdl
+
+// A sprite sheet: one surface holding many sprites (frames), each of which
+// is a named area of the surface. Keeping the sprites in one surface means
+// fewer surfaces to load and convert (see DisplayFormatCached).
+type Atlas struct {
+	Surface *Surface
+	frames  map[string]Rect
+}
+
+// Creates an atlas without frames for the given surface.
+func NewAtlas(surface *Surface) *Atlas {
+	return &Atlas{Surface: surface, frames: make(map[string]Rect)}
+}
+
+// Adds a frame, or replaces the frame with the same name.
+func (a *Atlas) AddFrame(name string, r Rect) {
+	a.frames[name] = r
+}
+
+// Returns the area of the named frame.
+func (a *Atlas) Frame(name string) (r Rect, ok bool) {
+	r, ok = a.frames[name]
+	return
+}
+
+// Blits the named frame to dst, with its top left corner at (x, y).
+// Returns -1 (and sets the SDL error) if there is no frame with that name,
+// otherwise the result of Blit.
+func (a *Atlas) Blit(dst *Surface, name string, x, y int) int {
+	srcrect, ok := a.frames[name]
+	if !ok {
+		SetError("Atlas: unknown frame " + name)
+		return -1
+	}
+
+	return dst.Blit(&Rect{X: int16(x), Y: int16(y)}, a.Surface, &srcrect)
+}
@@ -0,0 +1,207 @@
+package sdl
+
+// How the pixels of a surface are combined with the destination when it is blitted.
+type BlendMode int
+
+const (
+	BlendNone  BlendMode = iota // Copy the pixels
+	BlendAlpha                  // Alpha blending (SRCALPHA)
+	BlendAdd                    // Add the colors, scaled by alpha (see BlitAdditive)
+)
+
+// Sets how the surface is blended when it is the source of Blit.
+// Returns 0 on success, -1 on error.
+//
+// BlendNone and BlendAlpha turn alpha blending off and on (see SetAlphaMod).
+// SDL 1.2 has no additive blending, so with BlendAdd, Blit calls BlitAdditive,
+// which is done in Go and is much slower than a normal blit; so does BlitBatch.
+func (s *Surface) SetBlendMode(mode BlendMode) int {
+	if s.Format == nil {
+		SetError("SetBlendMode: nil pixel format")
+		return -1
+	}
+
+	var err error
+
+	switch mode {
+	case BlendNone:
+		_, _, err = s.SetAlphaMod(s.Format.Alpha, false)
+	case BlendAlpha:
+		_, _, err = s.SetAlphaMod(s.Format.Alpha, true)
+	case BlendAdd:
+	default:
+		SetError("SetBlendMode: unknown blend mode")
+		return -1
+	}
+
+	if err != nil {
+		return -1
+	}
+
+	s.mutex.Lock()
+	s.blendMode = mode
+	s.mutex.Unlock()
+
+	return 0
+}
+
+// Returns the blend mode set by SetBlendMode.
+func (s *Surface) getBlendMode() BlendMode {
+	s.mutex.RLock()
+	mode := s.blendMode
+	s.mutex.RUnlock()
+	return mode
+}
+
+// Blits the source surface to the destination surface, adding the colors of
+// the source pixels to those of the destination (saturating at white),
+// which is the usual way to draw glows, sparks and other light effects.
+//
+// The source colors are scaled by their alpha, or by the per-surface alpha
+// if SRCALPHA is set and the source has no alpha channel. Pixels matching
+// the colorkey are skipped. The rectangles are clipped like in Blit, and
+// dstrect is set to the area that was actually blitted to.
+// Returns 0 on success, -1 on error.
+func (dst *Surface) BlitAdditive(dstrect *Rect, src *Surface, srcrect *Rect) int {
+	// Source area, clipped to the source surface
+	sx, sy, w, h := 0, 0, int(src.W), int(src.H)
+	if srcrect != nil {
+		sx, sy, w, h = int(srcrect.X), int(srcrect.Y), int(srcrect.W), int(srcrect.H)
+	}
+	dx, dy := 0, 0
+	if dstrect != nil {
+		dx, dy = int(dstrect.X), int(dstrect.Y)
+	}
+	if sx < 0 {
+		w += sx
+		dx -= sx
+		sx = 0
+	}
+	if sy < 0 {
+		h += sy
+		dy -= sy
+		sy = 0
+	}
+	if sx+w > int(src.W) {
+		w = int(src.W) - sx
+	}
+	if sy+h > int(src.H) {
+		h = int(src.H) - sy
+	}
+
+	// Destination area, clipped to the clip rectangle of the destination
+	var clip Rect
+	dst.GetClipRect(&clip)
+	if d := int(clip.X) - dx; d > 0 {
+		w -= d
+		sx += d
+		dx += d
+	}
+	if d := int(clip.Y) - dy; d > 0 {
+		h -= d
+		sy += d
+		dy += d
+	}
+	if d := dx + w - int(clip.X) - int(clip.W); d > 0 {
+		w -= d
+	}
+	if d := dy + h - int(clip.Y) - int(clip.H); d > 0 {
+		h -= d
+	}
+
+	if w <= 0 || h <= 0 {
+		if dstrect != nil {
+			dstrect.W, dstrect.H = 0, 0
+		}
+		return 0
+	}
+
+	if dstrect != nil {
+		*dstrect = Rect{int16(dx), int16(dy), uint16(w), uint16(h)}
+	}
+
+	if src.Lock() != 0 {
+		return -1
+	}
+	if dst != src && dst.Lock() != 0 {
+		src.Unlock()
+		return -1
+	}
+	dst.MarkModified()
+
+	sf, df := src.Format, dst.Format
+	alpha := uint32(0xff)
+	if src.Flags&SRCALPHA != 0 && sf.Amask == 0 {
+		alpha = uint32(sf.Alpha)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pixel := src.getPixel(sx+x, sy+y)
+			if src.Flags&SRCCOLORKEY != 0 && pixel == sf.Colorkey {
+				continue
+			}
+
+			r, g, b, a := decodeRGBA(sf, pixel)
+			if sf.Amask != 0 {
+				alpha = uint32(a)
+			}
+			if alpha == 0 {
+				continue
+			}
+
+			dr, dg, db, da := decodeRGBA(df, dst.getPixel(dx+x, dy+y))
+			dr = addScaled(dr, r, alpha)
+			dg = addScaled(dg, g, alpha)
+			db = addScaled(db, b, alpha)
+
+			dst.setPixel(dx+x, dy+y, encodeRGBA(df, dr, dg, db, da))
+		}
+	}
+
+	if dst != src {
+		dst.Unlock()
+	}
+	src.Unlock()
+
+	return 0
+}
+
+// Returns d + s*alpha/255, saturated at 255.
+func addScaled(d, s uint8, alpha uint32) uint8 {
+	v := uint32(d) + uint32(s)*alpha/0xff
+	if v > 0xff {
+		return 0xff
+	}
+	return uint8(v)
+}
+
+// Gets the RGBA components of a pixel in Go, like GetRGBA.
+func decodeRGBA(f *PixelFormat, pixel uint32) (r, g, b, a uint8) {
+	if f.Palette != nil {
+		colors := paletteColors(f.Palette)
+		if pixel < uint32(len(colors)) {
+			c := colors[pixel]
+			return c.R, c.G, c.B, 0xff
+		}
+		return 0, 0, 0, 0xff
+	}
+
+	r = uint8(ExpandByte[f.Rloss][(pixel&f.Rmask)>>f.Rshift])
+	g = uint8(ExpandByte[f.Gloss][(pixel&f.Gmask)>>f.Gshift])
+	b = uint8(ExpandByte[f.Bloss][(pixel&f.Bmask)>>f.Bshift])
+	a = uint8(ExpandByte[f.Aloss][(pixel&f.Amask)>>f.Ashift])
+	return
+}
+
+// Maps RGBA components to a pixel, like MapRGBA. Palettes are handled by MapRGBA.
+func encodeRGBA(f *PixelFormat, r, g, b, a uint8) uint32 {
+	if f.Palette != nil {
+		return MapRGBA(f, r, g, b, a)
+	}
+
+	return uint32(r>>f.Rloss)<<f.Rshift |
+		uint32(g>>f.Gloss)<<f.Gshift |
+		uint32(b>>f.Bloss)<<f.Bshift |
+		uint32(a>>f.Aloss)<<f.Ashift&f.Amask
+}
@@ -0,0 +1,108 @@
+package sdl
+
+import "image/color"
+
+// Maps any color.Color to a pixel format.
+//
+// Go colors are alpha-premultiplied with 16 bits per channel, while SDL
+// expects straight alpha with 8 bits per channel, so the color is converted
+// to color.NRGBA before it is passed to MapRGBA.
+func MapColor(format *PixelFormat, c color.Color) uint32 {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return MapRGBA(format, n.R, n.G, n.B, n.A)
+}
+
+// Commonly used colors, for use with MapColor.
+var (
+	Black       = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	White       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	Red         = color.RGBA{0xff, 0x00, 0x00, 0xff}
+	Green       = color.RGBA{0x00, 0xff, 0x00, 0xff}
+	Blue        = color.RGBA{0x00, 0x00, 0xff, 0xff}
+	Yellow      = color.RGBA{0xff, 0xff, 0x00, 0xff}
+	Magenta     = color.RGBA{0xff, 0x00, 0xff, 0xff}
+	Cyan        = color.RGBA{0x00, 0xff, 0xff, 0xff}
+	Transparent = color.RGBA{0x00, 0x00, 0x00, 0x00}
+)
+
+// Maps the color to a pixel format.
+//
+// Color mirrors SDL_Color, whose fourth byte is unused by SDL 1.2,
+// so the color is always mapped as fully opaque.
+func (c Color) Map(format *PixelFormat) uint32 {
+	return MapRGB(format, c.R, c.G, c.B)
+}
+
+// Implements color.Color, so that a Color can be used with MapColor
+// and the image/color package. The color is fully opaque.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	r = uint32(c.R) * 0x101
+	g = uint32(c.G) * 0x101
+	b = uint32(c.B) * 0x101
+	a = 0xffff
+	return
+}
+
+// Decodes a slice of pixels in the given format into dst, which must be at least
+// as long as src. This is equivalent to calling GetRGBA for each pixel.
+//
+// The decoding is done in Go using the format masks and ExpandByte, as shown
+// in the documentation of GetRGBA, and palettes are looked up directly.
+// This avoids one cgo call per pixel, which is by far the most expensive part
+// of GetRGBA, so it is much faster for whole scanlines or surfaces
+// (compare BenchmarkGetRGBABatch with BenchmarkGetRGBALoop).
+func GetRGBABatch(format *PixelFormat, src []uint32, dst []color.RGBA) {
+	dst = dst[:len(src)]
+
+	if format.Palette != nil {
+		colors := paletteColors(format.Palette)
+		for i, pixel := range src {
+			if pixel < uint32(len(colors)) {
+				c := colors[pixel]
+				dst[i] = color.RGBA{c.R, c.G, c.B, 0xff}
+			} else {
+				dst[i] = color.RGBA{0, 0, 0, 0xff}
+			}
+		}
+		return
+	}
+
+	r, g, b, a := ExpandByte[format.Rloss], ExpandByte[format.Gloss], ExpandByte[format.Bloss], ExpandByte[format.Aloss]
+
+	for i, pixel := range src {
+		dst[i] = color.RGBA{
+			uint8(r[(pixel&format.Rmask)>>format.Rshift]),
+			uint8(g[(pixel&format.Gmask)>>format.Gshift]),
+			uint8(b[(pixel&format.Bmask)>>format.Bshift]),
+			uint8(a[(pixel&format.Amask)>>format.Ashift]),
+		}
+	}
+}
+
+// Gets the RGB components of a pixel, like GetRGB, but without a cgo call
+// in the common cases.
+//
+// Truecolor pixels are decoded with the format masks and ExpandByte,
+// indexed pixels are looked up in the palette of the format. Only formats
+// that ExpandByte doesn't cover are passed on to GetRGB.
+func GetRGBFast(pixel uint32, format *PixelFormat) (r, g, b uint8) {
+	if format.Palette != nil {
+		colors := paletteColors(format.Palette)
+		if pixel < uint32(len(colors)) {
+			c := colors[pixel]
+			return c.R, c.G, c.B
+		}
+		return 0, 0, 0
+	}
+
+	if format.Rloss >= uint8(len(ExpandByte)) || format.Gloss >= uint8(len(ExpandByte)) ||
+		format.Bloss >= uint8(len(ExpandByte)) {
+		GetRGB(pixel, format, &r, &g, &b)
+		return
+	}
+
+	r = uint8(ExpandByte[format.Rloss][(pixel&format.Rmask)>>format.Rshift])
+	g = uint8(ExpandByte[format.Gloss][(pixel&format.Gmask)>>format.Gshift])
+	b = uint8(ExpandByte[format.Bloss][(pixel&format.Bmask)>>format.Bshift])
+	return
+}
@@ -0,0 +1,133 @@
+package sdl
+
+import (
+	"image/color"
+	"testing"
+)
+
+// Formats built by hand, so that the pure-Go paths can be tested without SDL
+var (
+	testPalette = []Color{{0, 0, 0, 0}, {255, 0, 0, 0}, {10, 20, 30, 0}}
+
+	testFormats = map[string]*PixelFormat{
+		"INDEX8": {
+			Palette:      &Palette{Ncolors: int32(len(testPalette)), Colors: &testPalette[0]},
+			BitsPerPixel: 8, BytesPerPixel: 1,
+			Rloss: 8, Gloss: 8, Bloss: 8, Aloss: 8,
+		},
+		"RGB565": {
+			BitsPerPixel: 16, BytesPerPixel: 2,
+			Rloss: 3, Gloss: 2, Bloss: 3, Aloss: 8,
+			Rshift: 11, Gshift: 5,
+			Rmask: 0xf800, Gmask: 0x07e0, Bmask: 0x001f,
+		},
+		"RGB888": {
+			BitsPerPixel: 24, BytesPerPixel: 3,
+			Aloss:  8,
+			Rshift: 16, Gshift: 8,
+			Rmask: 0xff0000, Gmask: 0x00ff00, Bmask: 0x0000ff,
+		},
+		"ARGB8888": {
+			BitsPerPixel: 32, BytesPerPixel: 4,
+			Rshift: 16, Gshift: 8, Ashift: 24,
+			Rmask: 0x00ff0000, Gmask: 0x0000ff00, Bmask: 0x000000ff, Amask: 0xff000000,
+		},
+	}
+)
+
+func TestGetRGBFast(t *testing.T) {
+	tests := []struct {
+		format  string
+		pixel   uint32
+		r, g, b uint8
+	}{
+		{"INDEX8", 0, 0, 0, 0},
+		{"INDEX8", 1, 255, 0, 0},
+		{"INDEX8", 2, 10, 20, 30},
+		{"INDEX8", 3, 0, 0, 0}, // outside the palette
+		{"RGB565", 0xf800, 255, 0, 0},
+		{"RGB565", 0x07e0, 0, 255, 0},
+		{"RGB565", 0x001f, 0, 0, 255},
+		{"RGB565", 0x8410, 131, 129, 131},
+		{"RGB888", 0x123456, 0x12, 0x34, 0x56},
+		{"RGB888", 0xffffff, 255, 255, 255},
+		{"ARGB8888", 0x80123456, 0x12, 0x34, 0x56},
+		{"ARGB8888", 0x00ff00ff, 255, 0, 255},
+	}
+
+	for _, test := range tests {
+		r, g, b := GetRGBFast(test.pixel, testFormats[test.format])
+		if r != test.r || g != test.g || b != test.b {
+			t.Errorf("%s pixel %#x: got (%d, %d, %d), want (%d, %d, %d)",
+				test.format, test.pixel, r, g, b, test.r, test.g, test.b)
+		}
+	}
+}
+
+// Compares GetRGBFast with the C implementation behind GetRGB
+// on surfaces created by SDL.
+func TestGetRGBFastMatchesGetRGB(t *testing.T) {
+	initHeadless(t)
+
+	surfaces := []*Surface{
+		CreateSurface(SWSURFACE, 1, 1, PixelFormatIndex8),
+		CreateSurface(SWSURFACE, 1, 1, PixelFormatRGB565),
+		CreateRGBSurface(SWSURFACE, 1, 1, 24, 0xff0000, 0x00ff00, 0x0000ff, 0),
+		CreateSurface(SWSURFACE, 1, 1, PixelFormatRGBA8888),
+	}
+
+	for _, s := range surfaces {
+		if s == nil {
+			t.Fatal(GetError())
+		}
+		defer s.Free()
+
+		for i := uint32(0); i < 1000; i++ {
+			pixel := i * 0x9e3779b9 // Spread over all bits
+			if s.Format.BitsPerPixel < 32 {
+				pixel &= 1<<s.Format.BitsPerPixel - 1
+			}
+
+			var want [3]uint8
+			GetRGB(pixel, s.Format, &want[0], &want[1], &want[2])
+
+			r, g, b := GetRGBFast(pixel, s.Format)
+			if [3]uint8{r, g, b} != want {
+				t.Errorf("%v pixel %#x: got (%d, %d, %d), want %v", s.Format, pixel, r, g, b, want)
+				break
+			}
+		}
+	}
+}
+
+// A scanline of 640 pixels
+func benchmarkPixels() []uint32 {
+	pixels := make([]uint32, 640)
+	for i := range pixels {
+		pixels[i] = uint32(i) * 0x9e3779b9
+	}
+	return pixels
+}
+
+func BenchmarkGetRGBABatch(b *testing.B) {
+	format := testFormats["ARGB8888"]
+	pixels := benchmarkPixels()
+	colors := make([]color.RGBA, len(pixels))
+
+	for i := 0; i < b.N; i++ {
+		GetRGBABatch(format, pixels, colors)
+	}
+}
+
+func BenchmarkGetRGBALoop(b *testing.B) {
+	format := testFormats["ARGB8888"]
+	pixels := benchmarkPixels()
+	colors := make([]color.RGBA, len(pixels))
+
+	for i := 0; i < b.N; i++ {
+		for j, pixel := range pixels {
+			c := &colors[j]
+			GetRGBA(pixel, format, &c.R, &c.G, &c.B, &c.A)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package sdl
+
+// Reports whether two surfaces have the same size and the same color
+// (including alpha) at every pixel. The pixel formats may differ,
+// pixels are compared by their RGBA components, pixels of formats without
+// alpha channel count as opaque. Useful for golden-image tests.
+func (s *Surface) Equal(other *Surface) bool {
+	return s.EqualWithTolerance(other, 0, 0)
+}
+
+// Like Equal, but allows for small differences, as caused by different
+// rounding or antialiasing on other platforms: a pixel differs if one of its
+// RGBA components differs by more than perChannel, and the surfaces are
+// considered equal if at most maxDiffPixels pixels differ.
+func (s *Surface) EqualWithTolerance(other *Surface, perChannel uint8, maxDiffPixels int) bool {
+	if s.W != other.W || s.H != other.H {
+		return false
+	}
+
+	f, of := s.Format, other.Format
+	diff := 0
+
+	if s.Lock() != 0 {
+		return false
+	}
+	if other != s && other.Lock() != 0 {
+		s.Unlock()
+		return false
+	}
+
+	for y := 0; y < int(s.H) && diff <= maxDiffPixels; y++ {
+		for x := 0; x < int(s.W); x++ {
+			r, g, b, a := decodeRGBA(f, s.getPixel(x, y))
+			or, og, ob, oa := decodeRGBA(of, other.getPixel(x, y))
+			if f.Amask == 0 && f.Palette == nil {
+				a = 0xff
+			}
+			if of.Amask == 0 && of.Palette == nil {
+				oa = 0xff
+			}
+
+			if channelDiff(r, or) > perChannel || channelDiff(g, og) > perChannel ||
+				channelDiff(b, ob) > perChannel || channelDiff(a, oa) > perChannel {
+				diff++
+			}
+		}
+	}
+
+	if other != s {
+		other.Unlock()
+	}
+	s.Unlock()
+
+	return diff <= maxDiffPixels
+}
+
+// Returns the absolute difference of two color components.
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
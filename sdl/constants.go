@@ -16,6 +16,19 @@ const (
 	INIT_EVENTTHREAD = C.SDL_INIT_EVENTTHREAD
 	INIT_EVERYTHING  = C.SDL_INIT_EVERYTHING
 
+	// SDL_image init flags (see ImgInit). The values are spelled out because
+	// the IMG_INIT_* constants are missing from SDL_image older than 1.2.10.
+
+	INIT_JPG  = 1
+	INIT_PNG  = 2
+	INIT_TIF  = 4
+	INIT_WEBP = 8
+
+	// byte orders
+
+	LIL_ENDIAN = C.SDL_LIL_ENDIAN
+	BIG_ENDIAN = C.SDL_BIG_ENDIAN
+
 	// application states
 
 	APPMOUSEFOCUS = C.SDL_APPMOUSEFOCUS
@@ -122,6 +135,12 @@ const (
 	QUITMASK            = C.SDL_QUITMASK
 	SYSWMEVENTMASK      = C.SDL_SYSWMEVENTMASK
 
+	// grab modes
+
+	GRAB_QUERY = C.SDL_GRAB_QUERY
+	GRAB_OFF   = C.SDL_GRAB_OFF
+	GRAB_ON    = C.SDL_GRAB_ON
+
 	// event state
 
 	QUERY   = C.SDL_QUERY
@@ -362,6 +381,7 @@ const (
 	K_POWER        = C.SDLK_POWER
 	K_EURO         = C.SDLK_EURO
 	K_UNDO         = C.SDLK_UNDO
+	K_LAST         = C.SDLK_LAST // Number of keys, not a key
 
 	// key mods
 
@@ -415,4 +435,14 @@ const (
 	BUTTON_WHEELDOWNMASK = 1 << (BUTTON_WHEELDOWN - 1)
 	BUTTON_X1MASK        = 1 << (BUTTON_X1 - 1)
 	BUTTON_X2MASK        = 1 << (BUTTON_X2 - 1)
+
+	// joystick power levels (the values of SDL 2's SDL_JoystickPowerLevel)
+
+	JOYSTICK_POWER_UNKNOWN = -1
+	JOYSTICK_POWER_EMPTY   = 0
+	JOYSTICK_POWER_LOW     = 1
+	JOYSTICK_POWER_MEDIUM  = 2
+	JOYSTICK_POWER_FULL    = 3
+	JOYSTICK_POWER_WIRED   = 4
+	JOYSTICK_POWER_MAX     = 5
 )
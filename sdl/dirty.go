@@ -0,0 +1,110 @@
+package sdl
+
+// Accumulates dirty regions of a screen so they can be updated
+// with a single UpdateRects call per frame.
+//
+// Overlapping or adjacent rectangles are merged into their bounding box
+// as they are added, which keeps the number of rectangles passed to SDL small.
+// The zero value is an empty tracker ready to use.
+type DirtyTracker struct {
+	rects []Rect
+}
+
+// Marks the given area as dirty. Empty rectangles are ignored.
+func (t *DirtyTracker) Add(r Rect) {
+	if r.W == 0 || r.H == 0 {
+		return
+	}
+
+	// Merging two rectangles can make the result touch a rectangle
+	// that neither of them touched before, so keep going until
+	// the new rectangle doesn't touch anything.
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(t.rects); i++ {
+			if rectsTouch(r, t.rects[i]) {
+				r = rectUnion(r, t.rects[i])
+				t.rects[i] = t.rects[len(t.rects)-1]
+				t.rects = t.rects[:len(t.rects)-1]
+				merged = true
+				break
+			}
+		}
+	}
+
+	t.rects = append(t.rects, r)
+}
+
+// Returns the current set of merged dirty rectangles.
+// The returned slice is only valid until the next call to Add, Flush or Reset.
+func (t *DirtyTracker) Rects() []Rect {
+	return t.rects
+}
+
+// Forgets all dirty rectangles.
+func (t *DirtyTracker) Reset() {
+	t.rects = t.rects[:0]
+}
+
+// Updates the dirty areas of the screen and resets the tracker.
+func (t *DirtyTracker) Flush(screen *Surface) {
+	screen.UpdateRects(t.rects)
+	t.Reset()
+}
+
+// Reports whether a and b overlap or share an edge.
+func rectsTouch(a, b Rect) bool {
+	return int(a.X) <= int(b.X)+int(b.W) && int(b.X) <= int(a.X)+int(a.W) &&
+		int(a.Y) <= int(b.Y)+int(b.H) && int(b.Y) <= int(a.Y)+int(a.H)
+}
+
+// Returns the bounding box of a and b.
+func rectUnion(a, b Rect) Rect {
+	x0, y0 := int(a.X), int(a.Y)
+	x1, y1 := int(a.X)+int(a.W), int(a.Y)+int(a.H)
+
+	if int(b.X) < x0 {
+		x0 = int(b.X)
+	}
+	if int(b.Y) < y0 {
+		y0 = int(b.Y)
+	}
+	if int(b.X)+int(b.W) > x1 {
+		x1 = int(b.X) + int(b.W)
+	}
+	if int(b.Y)+int(b.H) > y1 {
+		y1 = int(b.Y) + int(b.H)
+	}
+
+	return Rect{int16(x0), int16(y0), uint16(x1 - x0), uint16(y1 - y0)}
+}
+
+// A list of rectangles backed by a slice that is reused after Reset,
+// so that building the list of updated areas every frame doesn't allocate
+// once the slice has grown large enough (compare BenchmarkRectBuffer with
+// BenchmarkRectsFreshSlice).
+// The zero value is an empty buffer.
+type RectBuffer struct {
+	rects []Rect
+}
+
+// Empties the buffer, keeping its memory for reuse.
+func (b *RectBuffer) Reset() {
+	b.rects = b.rects[:0]
+}
+
+// Appends a rectangle.
+func (b *RectBuffer) Add(r Rect) {
+	b.rects = append(b.rects, r)
+}
+
+// Returns the rectangles added since the last Reset. The slice is reused
+// by the buffer, it is only valid until the next Reset.
+func (b *RectBuffer) Rects() []Rect {
+	return b.rects
+}
+
+// Updates the areas of the screen in the buffer, see UpdateRects.
+func (screen *Surface) UpdateRectBuffer(b *RectBuffer) {
+	screen.UpdateRects(b.rects)
+}
@@ -0,0 +1,102 @@
+package sdl
+
+import "testing"
+
+func TestRectsTouch(t *testing.T) {
+	tests := []struct {
+		a, b Rect
+		want bool
+	}{
+		{Rect{0, 0, 10, 10}, Rect{5, 5, 10, 10}, true},   // overlapping
+		{Rect{0, 0, 10, 10}, Rect{2, 2, 3, 3}, true},     // contained
+		{Rect{0, 0, 10, 10}, Rect{10, 0, 10, 10}, true},  // shared vertical edge
+		{Rect{0, 0, 10, 10}, Rect{0, 10, 10, 10}, true},  // shared horizontal edge
+		{Rect{0, 0, 10, 10}, Rect{10, 10, 5, 5}, true},   // shared corner
+		{Rect{0, 0, 10, 10}, Rect{11, 0, 10, 10}, false}, // gap on the right
+		{Rect{0, 0, 10, 10}, Rect{0, 11, 10, 10}, false}, // gap below
+		{Rect{-5, -5, 4, 4}, Rect{0, 0, 10, 10}, false},  // gap above and left
+	}
+
+	for _, test := range tests {
+		if got := rectsTouch(test.a, test.b); got != test.want {
+			t.Errorf("rectsTouch(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+		if got := rectsTouch(test.b, test.a); got != test.want {
+			t.Errorf("rectsTouch(%v, %v) = %v, want %v", test.b, test.a, got, test.want)
+		}
+	}
+}
+
+func TestRectUnion(t *testing.T) {
+	tests := []struct {
+		a, b, want Rect
+	}{
+		{Rect{0, 0, 10, 10}, Rect{5, 5, 10, 10}, Rect{0, 0, 15, 15}},
+		{Rect{0, 0, 10, 10}, Rect{2, 2, 3, 3}, Rect{0, 0, 10, 10}},
+		{Rect{0, 0, 10, 10}, Rect{10, 0, 10, 10}, Rect{0, 0, 20, 10}},
+		{Rect{-5, -5, 4, 4}, Rect{0, 0, 10, 10}, Rect{-5, -5, 15, 15}},
+	}
+
+	for _, test := range tests {
+		if got := rectUnion(test.a, test.b); got != test.want {
+			t.Errorf("rectUnion(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+		if got := rectUnion(test.b, test.a); got != test.want {
+			t.Errorf("rectUnion(%v, %v) = %v, want %v", test.b, test.a, got, test.want)
+		}
+	}
+}
+
+func TestDirtyTrackerAdd(t *testing.T) {
+	var tracker DirtyTracker
+
+	tracker.Add(Rect{0, 0, 10, 10})
+	tracker.Add(Rect{0, 0, 0, 10}) // empty, ignored
+	tracker.Add(Rect{15, 0, 5, 5})
+	if n := len(tracker.Rects()); n != 2 {
+		t.Fatalf("got %d rectangles for disjoint areas, want 2: %v", n, tracker.Rects())
+	}
+
+	// Touches only the first rectangle, but the merged box reaches the second
+	tracker.Add(Rect{10, 10, 10, 1})
+	rects := tracker.Rects()
+	if len(rects) != 1 || rects[0] != (Rect{0, 0, 20, 11}) {
+		t.Errorf("got %v after a chained merge, want [{0 0 20 11}]", rects)
+	}
+
+	tracker.Reset()
+	if n := len(tracker.Rects()); n != 0 {
+		t.Errorf("got %d rectangles after Reset, want 0", n)
+	}
+}
+
+// Sink for the benchmarks, so that the rectangle lists aren't optimized away
+var benchmarkRects []Rect
+
+// Builds the list of updated areas of a frame in a new slice,
+// the way it is done without RectBuffer.
+func BenchmarkRectsFreshSlice(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var rects []Rect
+		for j := 0; j < 64; j++ {
+			rects = append(rects, Rect{int16(j * 10), 0, 8, 8})
+		}
+		benchmarkRects = rects
+	}
+}
+
+// Builds the same list in a RectBuffer, which allocates only in the first frame.
+func BenchmarkRectBuffer(b *testing.B) {
+	b.ReportAllocs()
+
+	var buffer RectBuffer
+	for i := 0; i < b.N; i++ {
+		buffer.Reset()
+		for j := 0; j < 64; j++ {
+			buffer.Add(Rect{int16(j * 10), 0, 8, 8})
+		}
+		benchmarkRects = buffer.Rects()
+	}
+}
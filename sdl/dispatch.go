@@ -0,0 +1,157 @@
+package sdl
+
+// Routes events to registered handlers, as an alternative
+// to a type switch over the values received from Events.
+//
+// Several handlers can be registered for the same kind of event,
+// they are called in the order in which they were registered.
+// The zero value is a dispatcher without any handlers.
+type EventDispatcher struct {
+	quit        []func()
+	keyDown     []func(*KeyboardEvent)
+	keyUp       []func(*KeyboardEvent)
+	mouseButton []func(*MouseButtonEvent)
+	mouseMotion []func(*MouseMotionEvent)
+	active      []func(*ActiveEvent)
+	resize      []func(*VideoResizeEvent)
+	joyAxis     []func(*JoyAxisEvent)
+	joyButton   []func(*JoyButtonEvent)
+	joyHat      []func(*JoyHatEvent)
+	joyBall     []func(*JoyBallEvent)
+
+	stopped bool
+}
+
+// Registers a handler for QUIT events.
+func (d *EventDispatcher) OnQuit(h func()) { d.quit = append(d.quit, h) }
+
+// Registers a handler for KEYDOWN events.
+func (d *EventDispatcher) OnKeyDown(h func(*KeyboardEvent)) { d.keyDown = append(d.keyDown, h) }
+
+// Registers a handler for KEYUP events.
+func (d *EventDispatcher) OnKeyUp(h func(*KeyboardEvent)) { d.keyUp = append(d.keyUp, h) }
+
+// Registers a handler for MOUSEBUTTONDOWN and MOUSEBUTTONUP events.
+func (d *EventDispatcher) OnMouseButton(h func(*MouseButtonEvent)) {
+	d.mouseButton = append(d.mouseButton, h)
+}
+
+// Registers a handler for MOUSEMOTION events.
+func (d *EventDispatcher) OnMouseMotion(h func(*MouseMotionEvent)) {
+	d.mouseMotion = append(d.mouseMotion, h)
+}
+
+// Registers a handler for ACTIVEEVENT events.
+func (d *EventDispatcher) OnActive(h func(*ActiveEvent)) { d.active = append(d.active, h) }
+
+// Registers a handler for VIDEORESIZE events.
+func (d *EventDispatcher) OnResize(h func(*VideoResizeEvent)) { d.resize = append(d.resize, h) }
+
+// Registers a handler for JOYAXISMOTION events.
+func (d *EventDispatcher) OnJoyAxis(h func(*JoyAxisEvent)) { d.joyAxis = append(d.joyAxis, h) }
+
+// Registers a handler for JOYBUTTONDOWN and JOYBUTTONUP events.
+func (d *EventDispatcher) OnJoyButton(h func(*JoyButtonEvent)) {
+	d.joyButton = append(d.joyButton, h)
+}
+
+// Registers a handler for JOYHATMOTION events.
+func (d *EventDispatcher) OnJoyHat(h func(*JoyHatEvent)) { d.joyHat = append(d.joyHat, h) }
+
+// Registers a handler for JOYBALLMOTION events.
+func (d *EventDispatcher) OnJoyBall(h func(*JoyBallEvent)) { d.joyBall = append(d.joyBall, h) }
+
+// Calls the handlers registered for the event. The event is either
+// a value received from Events or a raw *Event.
+// Events without handlers are ignored.
+func (d *EventDispatcher) Dispatch(event interface{}) {
+	if raw, ok := event.(*Event); ok {
+		event = typedEvent(raw)
+	}
+
+	switch e := event.(type) {
+	case QuitEvent:
+		for _, h := range d.quit {
+			h()
+		}
+
+	case KeyboardEvent:
+		handlers := d.keyDown
+		if e.Type == KEYUP {
+			handlers = d.keyUp
+		}
+		for _, h := range handlers {
+			h(&e)
+		}
+
+	case MouseButtonEvent:
+		for _, h := range d.mouseButton {
+			h(&e)
+		}
+
+	case MouseMotionEvent:
+		for _, h := range d.mouseMotion {
+			h(&e)
+		}
+
+	case ActiveEvent:
+		for _, h := range d.active {
+			h(&e)
+		}
+
+	case VideoResizeEvent:
+		notifyResize()
+		for _, h := range d.resize {
+			h(&e)
+		}
+
+	case JoyAxisEvent:
+		for _, h := range d.joyAxis {
+			h(&e)
+		}
+
+	case JoyButtonEvent:
+		for _, h := range d.joyButton {
+			h(&e)
+		}
+
+	case JoyHatEvent:
+		for _, h := range d.joyHat {
+			h(&e)
+		}
+
+	case JoyBallEvent:
+		for _, h := range d.joyBall {
+			h(&e)
+		}
+	}
+}
+
+// Makes Run return after the event currently being dispatched.
+// Typically called from a quit handler.
+func (d *EventDispatcher) Stop() {
+	d.stopped = true
+}
+
+// Receives events from Events and dispatches them until Stop is called.
+// In single-threaded mode (see SetSingleThreaded) the events are polled
+// with PollEvent instead.
+func (d *EventDispatcher) Run() {
+	d.stopped = false
+
+	if !singleThreaded() {
+		for !d.stopped {
+			d.Dispatch(<-Events)
+		}
+		return
+	}
+
+	event := &Event{}
+	for !d.stopped {
+		if PollEvent(event) {
+			d.Dispatch(event)
+		} else {
+			Delay(poll_interval_ms)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package sdl
+
+import (
+	"math"
+	"sort"
+)
+
+// Drawing primitives in pure Go, available without SDL_gfx.
+// They honor the clip rectangle of the surface and work for any pixel format,
+// colors are pixels in the format of the surface (see MapRGBA).
+
+// The area drawing functions may write to: the clip rectangle of the surface,
+// as x0 <= x < x1 and y0 <= y < y1.
+type drawArea struct {
+	x0, y0, x1, y1 int
+}
+
+func (s *Surface) drawArea() drawArea {
+	var clip Rect
+	s.GetClipRect(&clip)
+	return drawArea{int(clip.X), int(clip.Y), int(clip.X) + int(clip.W), int(clip.Y) + int(clip.H)}
+}
+
+// Sets the pixel at (x, y) if it is inside the area. The surface must be locked.
+func (s *Surface) plot(area drawArea, x, y int, color uint32) {
+	if x >= area.x0 && x < area.x1 && y >= area.y0 && y < area.y1 {
+		s.setPixel(x, y, color)
+	}
+}
+
+// Draws a one pixel wide line from (x0, y0) to (x1, y1), both ends included,
+// with Bresenham's algorithm.
+func (s *Surface) DrawLine(x0, y0, x1, y1 int, color uint32) {
+	area := s.drawArea()
+
+	if s.Lock() != 0 {
+		return
+	}
+	s.MarkModified()
+	s.line(area, x0, y0, x1, y1, color)
+	s.Unlock()
+}
+
+func (s *Surface) line(area drawArea, x0, y0, x1, y1 int, color uint32) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		s.plot(area, x0, y0, color)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Draws the one pixel wide outline of a rectangle, on the pixels just inside
+// the rectangle (unlike FillRect, a nil rectangle isn't allowed).
+func (s *Surface) DrawRect(rect *Rect, color uint32) {
+	if rect.W == 0 || rect.H == 0 {
+		return
+	}
+
+	x0, y0 := int(rect.X), int(rect.Y)
+	x1, y1 := x0+int(rect.W)-1, y0+int(rect.H)-1
+	area := s.drawArea()
+
+	if s.Lock() != 0 {
+		return
+	}
+	s.MarkModified()
+	s.line(area, x0, y0, x1, y0, color)
+	s.line(area, x0, y1, x1, y1, color)
+	s.line(area, x0, y0, x0, y1, color)
+	s.line(area, x1, y0, x1, y1, color)
+	s.Unlock()
+}
+
+// Draws the one pixel wide outline of a circle with the midpoint algorithm.
+func (s *Surface) DrawCircle(cx, cy, radius int, color uint32) {
+	if radius < 0 {
+		return
+	}
+
+	area := s.drawArea()
+
+	if s.Lock() != 0 {
+		return
+	}
+	s.MarkModified()
+
+	x, y := radius, 0
+	err := 1 - radius
+	for x >= y {
+		// One point in each octant; on the diagonals and axes some of them
+		// coincide, which is harmless since they have the same color
+		s.plot(area, cx+x, cy+y, color)
+		s.plot(area, cx+y, cy+x, color)
+		s.plot(area, cx-y, cy+x, color)
+		s.plot(area, cx-x, cy+y, color)
+		s.plot(area, cx-x, cy-y, color)
+		s.plot(area, cx-y, cy-x, color)
+		s.plot(area, cx+y, cy-x, color)
+		s.plot(area, cx+x, cy-y, color)
+
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+
+	s.Unlock()
+}
+
+// Fills a polygon with the scanline algorithm. The polygon is closed
+// automatically, from the last point back to the first. Self-intersecting
+// polygons are filled with the even-odd rule, so overlapping parts
+// alternate between filled and empty.
+//
+// A pixel is filled if its center is inside the polygon, so polygons
+// sharing an edge don't overlap.
+func (s *Surface) FillPolygon(points []Point, color uint32) {
+	if len(points) < 3 {
+		return
+	}
+
+	area := s.drawArea()
+
+	minY, maxY := int(points[0].Y), int(points[0].Y)
+	for _, p := range points[1:] {
+		if int(p.Y) < minY {
+			minY = int(p.Y)
+		}
+		if int(p.Y) > maxY {
+			maxY = int(p.Y)
+		}
+	}
+	if minY < area.y0 {
+		minY = area.y0
+	}
+	if maxY > area.y1-1 {
+		maxY = area.y1 - 1
+	}
+
+	if s.Lock() != 0 {
+		return
+	}
+	s.MarkModified()
+
+	crossings := make([]float64, 0, len(points))
+	for y := minY; y <= maxY; y++ {
+		cy := float64(y) + 0.5
+
+		// Where the edges cross the center line of the row
+		crossings = crossings[:0]
+		for i, a := range points {
+			b := points[(i+1)%len(points)]
+			ay, by := float64(a.Y), float64(b.Y)
+			if (ay <= cy) == (by <= cy) {
+				continue
+			}
+			t := (cy - ay) / (by - ay)
+			crossings = append(crossings, float64(a.X)+t*float64(int(b.X)-int(a.X)))
+		}
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			x0 := int(math.Ceil(crossings[i] - 0.5))
+			x1 := int(math.Ceil(crossings[i+1] - 0.5))
+			if x0 < area.x0 {
+				x0 = area.x0
+			}
+			if x1 > area.x1 {
+				x1 = area.x1
+			}
+			for x := x0; x < x1; x++ {
+				s.setPixel(x, y, color)
+			}
+		}
+	}
+
+	s.Unlock()
+}
+
+// Draws one pixel wide lines connecting the points in turn. With closed set,
+// the last point is connected back to the first, giving a polygon outline.
+func (s *Surface) DrawLines(points []Point, closed bool, color uint32) {
+	if len(points) == 0 {
+		return
+	}
+
+	area := s.drawArea()
+
+	if s.Lock() != 0 {
+		return
+	}
+	s.MarkModified()
+
+	prev := points[0]
+	s.plot(area, int(prev.X), int(prev.Y), color)
+	for _, p := range points[1:] {
+		s.line(area, int(prev.X), int(prev.Y), int(p.X), int(p.Y), color)
+		prev = p
+	}
+	if closed && len(points) > 2 {
+		s.line(area, int(prev.X), int(prev.Y), int(points[0].X), int(points[0].Y), color)
+	}
+
+	s.Unlock()
+}
@@ -0,0 +1,71 @@
+package sdl
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Number of errors kept until they are received from Errors
+const errorBufferSize = 16
+
+var backgroundErrors = make(chan error, errorBufferSize)
+
+// Returns a channel delivering errors that happen in the background,
+// where they can't be returned to the caller: in the goroutine polling
+// events (for example a failing RecordEvents writer) and in callbacks
+// called by SDL from its own threads.
+//
+// Such callbacks are called through trampolines that recover panics
+// and deliver them here as errors, instead of crashing the program.
+// If the errors aren't received, only the first ones are kept
+// and the rest are dropped.
+func Errors() <-chan error {
+	return backgroundErrors
+}
+
+// Delivers an error to Errors without blocking.
+func reportError(err error) {
+	select {
+	case backgroundErrors <- err:
+	default:
+	}
+}
+
+// Recovers a panic and delivers it to Errors.
+// Trampolines for callbacks must call this in a deferred call.
+func recoverPanic() {
+	if v := recover(); v != nil {
+		if err, ok := v.(error); ok {
+			reportError(err)
+		} else {
+			reportError(fmt.Errorf("sdl: panic in callback: %v", v))
+		}
+	}
+}
+
+var logger atomic.Value // Holds a func(msg string)
+
+// Sets a function that receives the SDL error message (see GetError)
+// whenever one of the main functions of the package fails: Init,
+// InitSubSystem, SetVideoMode, CreateRGBSurface, Load, SaveBMP, Lock,
+// Unlock, Blit, FillRect, Flip, SetAlpha and SetColorKey. The message is
+// prefixed with the name of the function. A nil function turns logging off,
+// which is the default.
+func SetLogger(log func(msg string)) {
+	logger.Store(log)
+}
+
+// Passes the current SDL error to the logger, if there is one.
+// Must be called without holding GlobalMutex.
+func logError(function string) {
+	if log, _ := logger.Load().(func(msg string)); log != nil {
+		log(function + ": " + GetError())
+	}
+}
+
+// Passes a message to the logger, if there is one.
+func logMessage(msg string) {
+	if log, _ := logger.Load().(func(msg string)); log != nil {
+		log(msg)
+	}
+}
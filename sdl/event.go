@@ -1,65 +1,237 @@
 package sdl
 
-import "time"
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
 
 var events chan interface{} = make(chan interface{})
 
 // This channel delivers SDL events. Each object received from this channel
 // has one of the following types: sdl.QuitEvent, sdl.KeyboardEvent,
 // sdl.MouseButtonEvent, sdl.MouseMotionEvent, sdl.ActiveEvent,
-// sdl.ResizeEvent, sdl.JoyAxisEvent, sdl.JoyButtonEvent, sdl.JoyHatEvent,
+// sdl.VideoResizeEvent, sdl.JoyAxisEvent, sdl.JoyButtonEvent, sdl.JoyHatEvent,
 // sdl.JoyBallEvent
 var Events <-chan interface{} = events
 
 // Polling interval, in milliseconds
 const poll_interval_ms = 10
 
-// Polls SDL events in periodic intervals.
-// This function does not return.
+// Stops pollEvents, see stopPolling
+var pollerStop = make(chan chan struct{})
+
+// Polls SDL events in periodic intervals, until stopped by stopPolling.
 func pollEvents() {
 	// It is more efficient to create the event-object here once,
 	// rather than multiple times within the loop
 	event := &Event{}
+	next := &Event{}
+
+	ticker := time.NewTicker(poll_interval_ms * time.Millisecond)
+	defer ticker.Stop()
 
 	for {
 		for event.poll() {
-			switch event.Type {
-			case QUIT:
-				events <- *(*QuitEvent)(cast(event))
+			if event.Type == MOUSEMOTION && atomic.LoadInt32(&coalesceMotion) != 0 {
+				mergeMotion(event, next)
+			}
+
+			if e := typedEvent(event); e != nil {
+				recordEvent(e)
+				select {
+				case events <- e:
+				case done := <-pollerStop:
+					close(done)
+					return
+				}
+			}
+		}
 
-			case KEYDOWN, KEYUP:
-				events <- *(*KeyboardEvent)(cast(event))
+		applyMouseConfinement()
 
-			case MOUSEBUTTONDOWN, MOUSEBUTTONUP:
-				events <- *(*MouseButtonEvent)(cast(event))
+		select {
+		case <-ticker.C:
+		case done := <-pollerStop:
+			close(done)
+			return
+		}
+	}
+}
 
-			case MOUSEMOTION:
-				events <- *(*MouseMotionEvent)(cast(event))
+// Stops the goroutine running pollEvents and waits until it has returned.
+func stopPolling() {
+	done := make(chan struct{})
+	pollerStop <- done
+	<-done
+}
 
-			case JOYAXISMOTION:
-				events <- *(*JoyAxisEvent)(cast(event))
+// Converts a raw event to the corresponding value delivered by Events,
+// or returns nil if the event isn't delivered by Events.
+func typedEvent(event *Event) interface{} {
+	switch event.Type {
+	case QUIT:
+		return *(*QuitEvent)(cast(event))
 
-			case JOYBUTTONDOWN, JOYBUTTONUP:
-				events <- *(*JoyButtonEvent)(cast(event))
+	case KEYDOWN, KEYUP:
+		e := *(*KeyboardEvent)(cast(event))
+		learnScancode(&e.Keysym)
+		return e
 
-			case JOYHATMOTION:
-				events <- *(*JoyHatEvent)(cast(event))
+	case MOUSEBUTTONDOWN, MOUSEBUTTONUP:
+		return *(*MouseButtonEvent)(cast(event))
 
-			case JOYBALLMOTION:
-				events <- *(*JoyBallEvent)(cast(event))
+	case MOUSEMOTION:
+		return *(*MouseMotionEvent)(cast(event))
 
-			case ACTIVEEVENT:
-				events <- *(*ActiveEvent)(cast(event))
+	case JOYAXISMOTION:
+		return *(*JoyAxisEvent)(cast(event))
 
-			case VIDEORESIZE:
-				events <- *(*ResizeEvent)(cast(event))
-			}
-		}
+	case JOYBUTTONDOWN, JOYBUTTONUP:
+		return *(*JoyButtonEvent)(cast(event))
 
-		time.Sleep(poll_interval_ms * 1e6)
+	case JOYHATMOTION:
+		return *(*JoyHatEvent)(cast(event))
+
+	case JOYBALLMOTION:
+		return *(*JoyBallEvent)(cast(event))
+
+	case ACTIVEEVENT:
+		return *(*ActiveEvent)(cast(event))
+
+	case VIDEORESIZE:
+		e, _ := event.Resize()
+		return e
 	}
+
+	return nil
+}
+
+// Non-zero if motion events are merged, see CoalesceMouseMotion
+var coalesceMotion int32
+
+// Turns the merging of mouse motion events on or off. When it is on,
+// motion events that directly follow each other in the queue are delivered
+// by Events as a single event with the latest position and button state,
+// and with the relative motion (Xrel, Yrel) of all of them added up.
+// A mouse reporting at a high rate then sends at most one motion event
+// between other events, instead of hundreds per frame.
+//
+// Nothing is lost for code using the relative motion, as the sums are
+// delivered, but the path of the mouse between the positions is: a drawing
+// program sees fewer, longer strokes. The sums saturate at the range of int16.
+// PollEvent never merges events.
+func CoalesceMouseMotion(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&coalesceMotion, v)
+}
+
+// Merges the motion events following the motion event in the queue into it.
+// next is used as a buffer.
+func mergeMotion(event *Event, next *Event) {
+	m := (*MouseMotionEvent)(cast(event))
+	xrel, yrel := int(m.Xrel), int(m.Yrel)
+
+	for next.pollMotion() {
+		n := (*MouseMotionEvent)(cast(next))
+		xrel += int(n.Xrel)
+		yrel += int(n.Yrel)
+		m.State, m.X, m.Y = n.State, n.X, n.Y
+	}
+
+	m.Xrel = int16(clampInt(xrel, math.MinInt16, math.MaxInt16))
+	m.Yrel = int16(clampInt(yrel, math.MinInt16, math.MaxInt16))
+}
+
+// A VIDEORESIZE event as delivered by Events: the ResizeEvent of SDL together
+// with the size of the window before the resize, for example to compute
+// the factor by which the window was scaled.
+type VideoResizeEvent struct {
+	ResizeEvent       // The size requested by the user
+	OldW, OldH  int32 // The size before the resize, 0 if no video mode was set
+}
+
+// Returns a VIDEORESIZE event together with the size before the resize.
+// ok is false for other events.
+//
+// SDL doesn't report the previous size, it is the size set by SetVideoMode
+// or requested by a VIDEORESIZE event, whichever came last before the event
+// was polled. It is only known for the most recently polled VIDEORESIZE
+// event, so call Resize before polling the next event. Events delivers
+// the result of Resize, see VideoResizeEvent.
+func (event *Event) Resize() (e VideoResizeEvent, ok bool) {
+	if event.Type != VIDEORESIZE {
+		return e, false
+	}
+
+	e.ResizeEvent = *(*ResizeEvent)(cast(event))
+
+	lockGlobal()
+	e.OldW, e.OldH = resizedFromW, resizedFromH
+	unlockGlobal()
+
+	return e, true
 }
 
 func init() {
 	go pollEvents()
 }
+
+// Waits at most ms milliseconds for an event and stores it in ev.
+// Returns false if no event arrived in time.
+//
+// Normally the event is received from Events and converted back to a raw
+// event; events that can't be converted are skipped. The events are taken
+// from the same channel as elsewhere, so each event goes either to
+// WaitEventTimeout or to another receiver of Events, never to both.
+// In single-threaded mode (see SetSingleThreaded), in which Events
+// delivers nothing, this polls instead (see PollEvent). SDL 1.2 has no timed
+// wait, so it sleeps a few milliseconds between the polls to keep the CPU
+// usage low.
+func WaitEventTimeout(ev *Event, ms uint32) bool {
+	if !singleThreaded() {
+		timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+		defer timer.Stop()
+
+		for {
+			select {
+			case e := <-Events:
+				raw, ok := rawEvent(e)
+				if !ok {
+					continue
+				}
+
+				*ev = raw
+				if ev.Type == VIDEORESIZE {
+					notifyResize()
+				}
+				return true
+
+			case <-timer.C:
+				return false
+			}
+		}
+	}
+
+	start := GetTicks()
+
+	for {
+		if PollEvent(ev) {
+			return true
+		}
+
+		elapsed := GetTicks() - start
+		if elapsed >= ms {
+			return false
+		}
+
+		wait := ms - elapsed
+		if wait > poll_interval_ms {
+			wait = poll_interval_ms
+		}
+		Delay(wait)
+	}
+}
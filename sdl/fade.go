@@ -0,0 +1,59 @@
+package sdl
+
+// Maps the progress of an animation (from 0 to 1) to the progress
+// of the animated value (from 0 to 1).
+type Easing func(t float64) float64
+
+// Changes the value at a constant speed.
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+// Starts slowly, speeds up and slows down again at the end (smoothstep).
+func EaseInOut(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// Fades a surface in or out by changing its per-surface alpha over time.
+type Fader struct {
+	Surface  *Surface
+	From, To uint8  // Alpha at the start and at the end
+	Duration uint32 // In milliseconds
+	Start    uint32 // Time of the start (see GetTicks)
+	Easing   Easing // How the alpha changes over time, EaseLinear if nil
+}
+
+// Creates a fader starting now, which changes the alpha of the surface
+// from one value to the other in durationMs milliseconds.
+func NewFader(surface *Surface, from, to uint8, durationMs uint32) *Fader {
+	return &Fader{
+		Surface:  surface,
+		From:     from,
+		To:       to,
+		Duration: durationMs,
+		Start:    GetTicks(),
+		Easing:   EaseLinear,
+	}
+}
+
+// Sets the alpha of the surface for the time now (see GetTicks),
+// typically once per frame. Returns false when the fade is complete,
+// in which case the alpha is set to the final value.
+func (f *Fader) Apply(now uint32) bool {
+	elapsed := now - f.Start
+
+	t := 1.0
+	if elapsed < f.Duration {
+		t = float64(elapsed) / float64(f.Duration)
+	}
+
+	easing := f.Easing
+	if easing == nil {
+		easing = EaseLinear
+	}
+
+	alpha := float64(f.From) + (float64(f.To)-float64(f.From))*easing(t)
+	f.Surface.SetAlphaMod(uint8(alpha+0.5), true)
+
+	return t < 1
+}
@@ -0,0 +1,172 @@
+package sdl
+
+import "math"
+
+// Returns a copy of the surface blurred with a box filter, in which each
+// pixel is the average of the (2*radius+1)² pixels around it. Blurring a
+// silhouette gives a drop shadow, blurring a screenshot a soft background
+// for dialogs. Pixels outside the surface are taken from the nearest edge.
+// Only 32-bit surfaces are supported. Returns nil on error.
+func (s *Surface) BoxBlur(radius int) *Surface {
+	if radius < 0 {
+		SetError("BoxBlur: negative radius")
+		return nil
+	}
+
+	// The box filter is separable: a horizontal pass followed by
+	// a vertical one gives the same result as the square kernel
+	row := make([]float64, 2*radius+1)
+	column := make([][]float64, 2*radius+1)
+	for i := range row {
+		row[i] = 1
+		column[i] = []float64{1}
+	}
+
+	tmp := s.Convolve([][]float64{row})
+	if tmp == nil {
+		return nil
+	}
+	dst := tmp.Convolve(column)
+	tmp.Free()
+
+	return dst
+}
+
+// Returns a copy of the surface convolved with the kernel, which is given
+// as rows of weights centered on the pixel (at kernel[len(kernel)/2][len(kernel[0])/2]).
+// All four channels are filtered. The kernel is normalized, so its weights
+// don't need to add up to 1; if they add up to 0, as with edge detection
+// kernels, they are used as given. Pixels outside the surface are taken
+// from the nearest edge. Only 32-bit surfaces are supported.
+// Returns nil on error.
+func (s *Surface) Convolve(kernel [][]float64) *Surface {
+	if s.Format.BytesPerPixel != 4 {
+		SetError("Convolve: surface must have 32 bits per pixel")
+		return nil
+	}
+	if len(kernel) == 0 || len(kernel[0]) == 0 {
+		SetError("Convolve: empty kernel")
+		return nil
+	}
+
+	kw, kh := len(kernel[0]), len(kernel)
+	sum := 0.0
+	for _, row := range kernel {
+		if len(row) != kw {
+			SetError("Convolve: kernel rows differ in length")
+			return nil
+		}
+		for _, v := range row {
+			sum += v
+		}
+	}
+	if sum == 0 {
+		sum = 1
+	}
+
+	w, h := int(s.W), int(s.H)
+	dst := s.createLike(w, h)
+	if dst == nil {
+		return nil
+	}
+
+	f := s.Format
+
+	if s.Lock() != 0 {
+		dst.Free()
+		return nil
+	}
+	if dst.Lock() != 0 {
+		s.Unlock()
+		dst.Free()
+		return nil
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var acc [4]float64
+
+			for ky := 0; ky < kh; ky++ {
+				sy := clampInt(y+ky-kh/2, 0, h-1)
+				for kx := 0; kx < kw; kx++ {
+					weight := kernel[ky][kx]
+					if weight == 0 {
+						continue
+					}
+					sx := clampInt(x+kx-kw/2, 0, w-1)
+
+					r, g, b, a := decodeRGBA(f, s.getPixel(sx, sy))
+					acc[0] += weight * float64(r)
+					acc[1] += weight * float64(g)
+					acc[2] += weight * float64(b)
+					acc[3] += weight * float64(a)
+				}
+			}
+
+			var c [4]uint8
+			for i, v := range acc {
+				c[i] = uint8(clampInt(int(v/sum+0.5), 0, 0xff))
+			}
+			dst.setPixel(x, y, encodeRGBA(f, c[0], c[1], c[2], c[3]))
+		}
+	}
+
+	dst.Unlock()
+	s.Unlock()
+
+	return dst
+}
+
+// Returns v limited to the range [lo, hi].
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// Returns the color at the position (u, v) of the surface, interpolated
+// between the four nearest pixels (bilinear filtering), for smooth reads
+// in warps, lens effects and other procedural effects.
+//
+// The coordinates are relative to the size of the surface: (0, 0) is the
+// top left corner, (1, 1) the bottom right corner. Positions outside the
+// surface get the color of the nearest edge. The result is black if the
+// surface can't be locked.
+func (s *Surface) SampleBilinear(u, v float64) Color {
+	if s.Lock() != 0 {
+		return Color{}
+	}
+	r, g, b, _ := s.bilinear(u*float64(s.W)-0.5, v*float64(s.H)-0.5)
+	s.Unlock()
+
+	return Color{R: r, G: g, B: b}
+}
+
+// Interpolates the RGBA components between the four pixels around (x, y),
+// where pixel centers are at whole numbers. The surface must be locked.
+func (s *Surface) bilinear(x, y float64) (r, g, b, a uint8) {
+	w, h := int(s.W), int(s.H)
+	f := s.Format
+
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	wx, wy := x-float64(x0), y-float64(y0)
+	x1, y1 := clampInt(x0+1, 0, w-1), clampInt(y0+1, 0, h-1)
+	x0, y0 = clampInt(x0, 0, w-1), clampInt(y0, 0, h-1)
+
+	r00, g00, b00, a00 := decodeRGBA(f, s.getPixel(x0, y0))
+	r10, g10, b10, a10 := decodeRGBA(f, s.getPixel(x1, y0))
+	r01, g01, b01, a01 := decodeRGBA(f, s.getPixel(x0, y1))
+	r11, g11, b11, a11 := decodeRGBA(f, s.getPixel(x1, y1))
+
+	mix := func(c00, c10, c01, c11 uint8) uint8 {
+		top := float64(c00)*(1-wx) + float64(c10)*wx
+		bottom := float64(c01)*(1-wx) + float64(c11)*wx
+		return uint8(top*(1-wy) + bottom*wy + 0.5)
+	}
+
+	return mix(r00, r10, r01, r11), mix(g00, g10, g01, g11), mix(b00, b10, b01, b11), mix(a00, a10, a01, a11)
+}
@@ -0,0 +1,186 @@
+package sdl
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Returns the masks for a 32-bit surface whose pixels are stored as the
+// bytes R, G, B, A in memory (the layout of image.RGBA's Pix).
+//
+// The masks depend on the byte order of the machine, pass them directly
+// to CreateRGBSurface or CreateRGBSurfaceFrom.
+func RGBA8888Masks() (r, g, b, a uint32) {
+	if BYTEORDER() == BIG_ENDIAN {
+		return 0xff000000, 0x00ff0000, 0x0000ff00, 0x000000ff
+	}
+	return 0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000
+}
+
+// Returns the masks for a 32-bit surface whose pixels are stored as the
+// bytes A, R, G, B in memory.
+//
+// The masks depend on the byte order of the machine, pass them directly
+// to CreateRGBSurface or CreateRGBSurfaceFrom.
+func ARGB8888Masks() (r, g, b, a uint32) {
+	if BYTEORDER() == BIG_ENDIAN {
+		return 0x00ff0000, 0x0000ff00, 0x000000ff, 0xff000000
+	}
+	return 0x0000ff00, 0x00ff0000, 0xff000000, 0x000000ff
+}
+
+// Returns the masks for a 16-bit surface with 5 bits of red, 6 bits of green
+// and 5 bits of blue, and no alpha channel.
+//
+// A 565 pixel is a packed native-endian uint16, so unlike the 32-bit layouts
+// the masks are the same on every machine.
+func RGB565Masks() (r, g, b, a uint32) {
+	return 0xf800, 0x07e0, 0x001f, 0
+}
+
+// Creates an empty surface of the given size with exactly the same pixel format
+// as s (bits per pixel, masks and palette), so that blits between the two
+// surfaces need no conversion. Useful for intermediate buffers matching
+// the screen. Returns nil on error.
+func (s *Surface) CreateCompatible(w, h int) *Surface {
+	f := s.Format
+	c := CreateRGBSurface(s.Flags&HWSURFACE, w, h, int(f.BitsPerPixel), f.Rmask, f.Gmask, f.Bmask, f.Amask)
+	if c == nil {
+		return nil
+	}
+
+	if f.Palette != nil {
+		c.SetColors(paletteColors(f.Palette), 0)
+	}
+
+	return c
+}
+
+// Returns the number of bits per pixel of the surface.
+func (s *Surface) BitsPerPixel() int {
+	return int(s.Format.BitsPerPixel)
+}
+
+// Returns the number of bytes per pixel of the surface.
+func (s *Surface) BytesPerPixel() int {
+	return int(s.Format.BytesPerPixel)
+}
+
+// Returns the size of the pixel data of the surface in bytes (Pitch*H),
+// including the padding at the end of the rows.
+func (s *Surface) ByteSize() int {
+	return int(s.Pitch) * int(s.H)
+}
+
+// A named pixel format, see CreateSurface.
+type PixelFormatEnum int
+
+const (
+	PixelFormatRGBA8888 PixelFormatEnum = iota // 32 bits, bytes R, G, B, A in memory (see RGBA8888Masks)
+	PixelFormatARGB8888                        // 32 bits, bytes A, R, G, B in memory (see ARGB8888Masks)
+	PixelFormatRGB565                          // 16 bits, no alpha (see RGB565Masks)
+	PixelFormatIndex8                          // 8 bits, palettized
+)
+
+// Bits per pixel and masks of the named pixel formats
+var pixelFormats = map[PixelFormatEnum]struct {
+	bpp   int
+	masks func() (r, g, b, a uint32)
+}{
+	PixelFormatRGBA8888: {32, RGBA8888Masks},
+	PixelFormatARGB8888: {32, ARGB8888Masks},
+	PixelFormatRGB565:   {16, RGB565Masks},
+	PixelFormatIndex8:   {8, func() (r, g, b, a uint32) { return }},
+}
+
+// Creates an empty surface with a named pixel format, like CreateRGBSurface
+// but without having to work out the bits per pixel and the masks.
+// Returns nil on error.
+//
+// 8-bit surfaces get the default palette of SDL; set the colors with SetColors.
+func CreateSurface(flags uint32, w, h int, format PixelFormatEnum) *Surface {
+	pf, ok := pixelFormats[format]
+	if !ok {
+		SetError("CreateSurface: unknown pixel format")
+		return nil
+	}
+
+	r, g, b, a := pf.masks()
+	return CreateRGBSurface(flags, w, h, pf.bpp, r, g, b, a)
+}
+
+// Reports whether two pixel formats store pixels the same way (the same bits
+// per pixel and masks), so that pixels can be copied between them without
+// conversion. Palettes aren't compared.
+func (f *PixelFormat) Equal(other *PixelFormat) bool {
+	return f.BitsPerPixel == other.BitsPerPixel &&
+		f.Rmask == other.Rmask && f.Gmask == other.Gmask &&
+		f.Bmask == other.Bmask && f.Amask == other.Amask
+}
+
+// Describes the pixel format, in the style of the PixelFormat* names:
+// "INDEX8" for palettized formats, the channels in the order of the bytes
+// in memory for formats with 8 bits per channel, like "RGBA8888" or "BGR888"
+// (X is an unused byte), and the channels from the highest to the lowest bits
+// of the pixel value for other formats, like "RGB565" or "XRGB1555".
+func (f *PixelFormat) String() string {
+	if f.Palette != nil || f.Rmask|f.Gmask|f.Bmask|f.Amask == 0 {
+		return "INDEX" + strconv.Itoa(int(f.BitsPerPixel))
+	}
+
+	type channel struct {
+		name        byte
+		shift, bits int
+	}
+	var channels []channel
+	for _, c := range []struct {
+		name        byte
+		mask        uint32
+		shift, loss uint8
+	}{
+		{'R', f.Rmask, f.Rshift, f.Rloss},
+		{'G', f.Gmask, f.Gshift, f.Gloss},
+		{'B', f.Bmask, f.Bshift, f.Bloss},
+		{'A', f.Amask, f.Ashift, f.Aloss},
+	} {
+		if c.mask != 0 {
+			channels = append(channels, channel{c.name, int(c.shift), 8 - int(c.loss)})
+		}
+	}
+
+	bytesPerPixel := int(f.BytesPerPixel)
+	byteAligned := bytesPerPixel >= 3
+	for _, c := range channels {
+		if c.bits != 8 || c.shift%8 != 0 {
+			byteAligned = false
+		}
+	}
+
+	if byteAligned {
+		names := make([]byte, bytesPerPixel)
+		for i := range names {
+			names[i] = 'X'
+		}
+		for _, c := range channels {
+			i := c.shift / 8
+			if BYTEORDER() == BIG_ENDIAN {
+				i = bytesPerPixel - 1 - i
+			}
+			names[i] = c.name
+		}
+		return string(names) + strings.Repeat("8", bytesPerPixel)
+	}
+
+	sort.Slice(channels, func(i, j int) bool { return channels[i].shift > channels[j].shift })
+
+	names, bits := "", ""
+	if top := channels[0].shift + channels[0].bits; top < int(f.BitsPerPixel) {
+		names, bits = "X", strconv.Itoa(int(f.BitsPerPixel)-top)
+	}
+	for _, c := range channels {
+		names += string(c.name)
+		bits += strconv.Itoa(c.bits)
+	}
+	return names + bits
+}
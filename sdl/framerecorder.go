@@ -0,0 +1,208 @@
+package sdl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Saves frames as numbered image files (frame000000.png, frame000001.png, ...),
+// which tools like ffmpeg can turn into a video, for trailers or recordings
+// of bugs. The files are encoded and written by a background goroutine,
+// so capturing a frame costs little more than copying the screen.
+// That goroutine makes no SDL calls, so the recorder can be used in
+// single-threaded mode (see SetSingleThreaded).
+type FrameRecorder struct {
+	dir    string
+	ext    string
+	frames chan recordedFrame
+	done   sync.WaitGroup
+
+	mutex   sync.Mutex
+	next    int // Number of the next captured frame
+	dropped int
+	closed  bool
+}
+
+type recordedFrame struct {
+	image  *image.NRGBA
+	number int
+}
+
+// Creates a frame recorder writing to the directory dir, which is created
+// if needed. The format is "bmp" or "png"; BMP files are much faster
+// to write, PNG files much smaller. At most queueSize frames wait to be
+// written, see Capture. Close the recorder when done.
+func NewFrameRecorder(dir, format string, queueSize int) (*FrameRecorder, error) {
+	if format != "bmp" && format != "png" {
+		return nil, errors.New("NewFrameRecorder: unknown format " + format)
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	r := &FrameRecorder{
+		dir:    dir,
+		ext:    format,
+		frames: make(chan recordedFrame, queueSize),
+	}
+
+	r.done.Add(1)
+	go r.write()
+
+	return r, nil
+}
+
+// Copies the screen (or any other surface) into an image and queues it
+// to be written. Call it after drawing a frame, before Flip.
+//
+// If the writing goroutine falls behind and the queue is full, the frame is
+// dropped rather than stalling the render loop, and a warning is passed
+// to the logger (see SetLogger). The numbers of the written files have no
+// gaps, so a dropped frame just makes the video a little shorter.
+func (r *FrameRecorder) Capture(screen *Surface) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	img := surfaceNRGBA(screen)
+	if img == nil {
+		logError("FrameRecorder.Capture")
+		return
+	}
+
+	select {
+	case r.frames <- recordedFrame{img, r.next}:
+		r.next++
+	default:
+		r.dropped++
+		logMessage(fmt.Sprintf("FrameRecorder.Capture: queue full, frame dropped (%d so far)", r.dropped))
+	}
+}
+
+// Returns the number of frames dropped by Capture because the queue was full.
+func (r *FrameRecorder) Dropped() int {
+	r.mutex.Lock()
+	dropped := r.dropped
+	r.mutex.Unlock()
+	return dropped
+}
+
+// Waits until the queued frames are written and stops the recorder.
+// Errors while writing are delivered by Errors.
+func (r *FrameRecorder) Close() {
+	r.mutex.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.frames)
+	}
+	r.mutex.Unlock()
+
+	r.done.Wait()
+}
+
+// Writes the queued frames until the recorder is closed.
+func (r *FrameRecorder) write() {
+	defer r.done.Done()
+
+	for frame := range r.frames {
+		path := filepath.Join(r.dir, fmt.Sprintf("frame%06d.%s", frame.number, r.ext))
+
+		var err error
+		if r.ext == "bmp" {
+			err = writeImage(path, frame.image, writeBMP)
+		} else {
+			err = writeImage(path, frame.image, png.Encode)
+		}
+		if err != nil {
+			reportError(err)
+		}
+	}
+}
+
+// Copies the pixels of the surface into a new image.
+// Returns nil on error.
+func surfaceNRGBA(s *Surface) *image.NRGBA {
+	f := s.Format
+	img := image.NewNRGBA(image.Rect(0, 0, int(s.W), int(s.H)))
+
+	if s.Lock() != 0 {
+		return nil
+	}
+	for y := 0; y < int(s.H); y++ {
+		for x := 0; x < int(s.W); x++ {
+			r, g, b, a := decodeRGBA(f, s.getPixel(x, y))
+			if f.Amask == 0 {
+				a = 0xff
+			}
+			i := img.PixOffset(x, y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = r, g, b, a
+		}
+	}
+	s.Unlock()
+
+	return img
+}
+
+// Creates the file and writes the image into it with encode.
+func writeImage(path string, img *image.NRGBA, encode func(w io.Writer, img image.Image) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Writes the image as a 24-bit BMP, like SaveBMP does. The alpha is dropped.
+func writeBMP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	pitch := (3*b.Dx() + 3) &^ 3 // Rows are padded to 4 bytes
+	size := pitch * b.Dy()
+
+	const headerSize = 14 + 40
+	header := []interface{}{
+		// BITMAPFILEHEADER
+		[2]byte{'B', 'M'}, uint32(headerSize + size), uint32(0), uint32(headerSize),
+		// BITMAPINFOHEADER, with a positive height for rows stored bottom-up
+		uint32(40), int32(b.Dx()), int32(b.Dy()), uint16(1), uint16(24),
+		uint32(0), uint32(size), int32(2835), int32(2835), uint32(0), uint32(0),
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, field := range header {
+		if err := binary.Write(bw, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+
+	row := make([]byte, pitch)
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, y)).(color.NRGBA)
+			row[3*x], row[3*x+1], row[3*x+2] = c.B, c.G, c.R
+		}
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
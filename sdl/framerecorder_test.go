@@ -0,0 +1,43 @@
+package sdl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWriteBMP(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.NRGBA{0, 255, 0, 255})
+	img.Set(0, 1, color.NRGBA{0, 0, 255, 255})
+	img.Set(1, 1, color.NRGBA{1, 2, 3, 128})
+
+	var buf bytes.Buffer
+	if err := writeBMP(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Rows of 6 bytes are padded to 8
+	if len(data) != 54+2*8 {
+		t.Fatalf("got %d bytes, want %d", len(data), 54+2*8)
+	}
+	if string(data[:2]) != "BM" || binary.LittleEndian.Uint32(data[2:]) != uint32(len(data)) {
+		t.Errorf("bad file header % x", data[:14])
+	}
+	if w, h := binary.LittleEndian.Uint32(data[18:]), binary.LittleEndian.Uint32(data[22:]); w != 2 || h != 2 {
+		t.Errorf("got size %dx%d, want 2x2", w, h)
+	}
+
+	// The bottom row comes first, in BGR order
+	want := []byte{
+		255, 0, 0, 3, 2, 1, 0, 0,
+		0, 0, 255, 0, 255, 0, 0, 0,
+	}
+	if !bytes.Equal(data[54:], want) {
+		t.Errorf("got pixels % x, want % x", data[54:], want)
+	}
+}
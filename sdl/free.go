@@ -0,0 +1,30 @@
+package sdl
+
+import "sync"
+
+var pendingFrees struct {
+	sync.Mutex
+	surfaces []*Surface
+}
+
+// Queues a surface to be freed by the next call of ProcessFrees.
+// Safe to call from any goroutine, typically from a worker goroutine
+// that produced the surface and is done with it.
+func DeferFree(s *Surface) {
+	pendingFrees.Lock()
+	pendingFrees.surfaces = append(pendingFrees.surfaces, s)
+	pendingFrees.Unlock()
+}
+
+// Frees the surfaces queued by DeferFree. Call it from the goroutine
+// making the SDL calls, for example once per frame in the main loop.
+func ProcessFrees() {
+	pendingFrees.Lock()
+	surfaces := pendingFrees.surfaces
+	pendingFrees.surfaces = nil
+	pendingFrees.Unlock()
+
+	for _, s := range surfaces {
+		s.Free()
+	}
+}
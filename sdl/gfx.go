@@ -1,13 +1,23 @@
+//go:build sdlgfx
+// +build sdlgfx
+
 package sdl
 
 // #cgo pkg-config: SDL_gfx
 // #include <SDL_rotozoom.h>
 import "C"
 
+// Returns a copy of the surface scaled by zoomX and zoomY, with smoothing
+// (interpolation) if smooth is set. Negative factors flip the surface.
+// Returns nil on error.
+//
+// This uses zoomSurface of SDL_gfx, which is only linked when building with
+// the sdlgfx tag (go build -tags sdlgfx). Without the tag a pure Go
+// version is used.
 func (s *Surface) Zoom(zoomX, zoomY float64, smooth bool) *Surface {
 	cSmooth := C.int(0)
 	if smooth {
 		cSmooth = C.int(1)
 	}
-	return wrap(C.zoomSurface(s.cSurface, C.double(zoomX), C.double(zoomY), cSmooth))
+	return wrap(C.zoomSurface(s.cSurface, C.double(zoomX), C.double(zoomY), cSmooth)).account(false)
 }
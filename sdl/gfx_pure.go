@@ -0,0 +1,171 @@
+//go:build !sdlgfx
+// +build !sdlgfx
+
+package sdl
+
+import "math"
+
+// Returns a copy of the surface scaled by zoomX and zoomY, with smoothing
+// (bilinear interpolation) if smooth is set. Negative factors flip the surface.
+// Returns nil on error.
+//
+// This is the pure Go version, used unless building with the sdlgfx tag
+// (go build -tags sdlgfx), which uses zoomSurface of SDL_gfx instead.
+// Like zoomSurface, it keeps the pixel format of 8-bit and 32-bit surfaces
+// and returns other surfaces as 32-bit surfaces with alpha channel
+// (see PixelFormatRGBA8888), in which the pixels matching the colorkey
+// become transparent.
+//
+// Smoothing leaves the pixels matching the colorkey out of the color, so that
+// it doesn't bleed into the edges of a sprite. There the alpha fades out in
+// the converted copies, while copies of 32-bit surfaces keep the colorkey
+// where it covers at least half of the interpolated pixels. 8-bit surfaces are
+// never smoothed, since interpolating palette indexes makes no sense.
+func (s *Surface) Zoom(zoomX, zoomY float64, smooth bool) *Surface {
+	if zoomX == 0 || zoomY == 0 {
+		SetError("Zoom: zero zoom factor")
+		return nil
+	}
+
+	w, h := int(s.W), int(s.H)
+	dw := int(math.Abs(zoomX)*float64(w) + 0.5)
+	dh := int(math.Abs(zoomY)*float64(h) + 0.5)
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	var dst *Surface
+	convert := s.Format.BitsPerPixel != 8 && s.Format.BitsPerPixel != 32
+	if convert {
+		dst = CreateSurface(SWSURFACE, dw, dh, PixelFormatRGBA8888)
+		if dst != nil {
+			dst.SetAlpha(SRCALPHA, 0xff)
+		}
+	} else {
+		dst = s.createLike(dw, dh)
+	}
+	if dst == nil {
+		return nil
+	}
+
+	if s.Format.Palette != nil {
+		smooth = false
+	}
+
+	f, df := s.Format, dst.Format
+	keyed := s.Flags&SRCCOLORKEY != 0
+	scaleX, scaleY := float64(w)/float64(dw), float64(h)/float64(dh)
+
+	if s.Lock() != 0 {
+		dst.Free()
+		return nil
+	}
+	if dst.Lock() != 0 {
+		s.Unlock()
+		dst.Free()
+		return nil
+	}
+
+	for y := 0; y < dh; y++ {
+		ty := y
+		if zoomY < 0 {
+			ty = dh - 1 - y
+		}
+
+		for x := 0; x < dw; x++ {
+			tx := x
+			if zoomX < 0 {
+				tx = dw - 1 - x
+			}
+
+			if !smooth {
+				sx := clampInt(int(float64(x)*scaleX), 0, w-1)
+				sy := clampInt(int(float64(y)*scaleY), 0, h-1)
+				pixel := s.getPixel(sx, sy)
+
+				switch {
+				case !convert:
+					dst.setPixel(tx, ty, pixel)
+				case keyed && pixel == f.Colorkey:
+					dst.setPixel(tx, ty, 0)
+				default:
+					r, g, b, a := decodeRGBA(f, pixel)
+					if f.Amask == 0 {
+						a = 0xff
+					}
+					dst.setPixel(tx, ty, encodeRGBA(df, r, g, b, a))
+				}
+				continue
+			}
+
+			// Position of the center of the destination pixel in the source
+			fx := (float64(x)+0.5)*scaleX - 0.5
+			fy := (float64(y)+0.5)*scaleY - 0.5
+			r, g, b, a, coverage := s.bilinearOpaque(fx, fy)
+
+			switch {
+			case convert:
+				a = uint8(float64(a)*coverage + 0.5)
+				dst.setPixel(tx, ty, encodeRGBA(df, r, g, b, a))
+			case coverage < 0.5:
+				dst.setPixel(tx, ty, f.Colorkey)
+			default:
+				dst.setPixel(tx, ty, encodeRGBA(df, r, g, b, a))
+			}
+		}
+	}
+
+	dst.Unlock()
+	s.Unlock()
+
+	return dst
+}
+
+// Interpolates like bilinear, but leaves out the pixels matching the colorkey
+// (if SRCCOLORKEY is set), so that it doesn't bleed into the color. coverage is
+// the weight of the other pixels, from 0 (only colorkey) to 1 (no colorkey).
+// Pixels of formats without alpha channel are opaque. The surface must be locked.
+func (s *Surface) bilinearOpaque(x, y float64) (r, g, b, a uint8, coverage float64) {
+	w, h := int(s.W), int(s.H)
+	f := s.Format
+	keyed := s.Flags&SRCCOLORKEY != 0
+
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	wx, wy := x-float64(x0), y-float64(y0)
+	x1, y1 := clampInt(x0+1, 0, w-1), clampInt(y0+1, 0, h-1)
+	x0, y0 = clampInt(x0, 0, w-1), clampInt(y0, 0, h-1)
+
+	var acc [4]float64
+
+	add := func(px, py int, weight float64) {
+		pixel := s.getPixel(px, py)
+		if weight == 0 || keyed && pixel == f.Colorkey {
+			return
+		}
+
+		pr, pg, pb, pa := decodeRGBA(f, pixel)
+		if f.Amask == 0 {
+			pa = 0xff
+		}
+		acc[0] += weight * float64(pr)
+		acc[1] += weight * float64(pg)
+		acc[2] += weight * float64(pb)
+		acc[3] += weight * float64(pa)
+		coverage += weight
+	}
+
+	add(x0, y0, (1-wx)*(1-wy))
+	add(x1, y0, wx*(1-wy))
+	add(x0, y1, (1-wx)*wy)
+	add(x1, y1, wx*wy)
+
+	if coverage == 0 {
+		return 0, 0, 0, 0, 0
+	}
+
+	return uint8(acc[0]/coverage + 0.5), uint8(acc[1]/coverage + 0.5),
+		uint8(acc[2]/coverage + 0.5), uint8(acc[3]/coverage + 0.5), coverage
+}
@@ -0,0 +1,54 @@
+//go:build !sdlgfx
+// +build !sdlgfx
+
+package sdl
+
+import "testing"
+
+func TestZoomColorKey(t *testing.T) {
+	initHeadless(t)
+
+	// A red pixel next to a magenta colorkey pixel
+	src := CreateSurface(SWSURFACE, 2, 1, PixelFormatRGB565)
+	if src == nil {
+		t.Fatal(GetError())
+	}
+	defer src.Free()
+
+	key := src.MapRGB(255, 0, 255)
+	src.FillRect(nil, key)
+	src.FillRect(&Rect{0, 0, 1, 1}, src.MapRGB(255, 0, 0))
+	src.SetColorKey(SRCCOLORKEY, key)
+
+	for _, smooth := range []bool{false, true} {
+		dst := src.Zoom(2, 1, smooth)
+		if dst == nil {
+			t.Fatal(GetError())
+		}
+
+		// Like zoomSurface of SDL_gfx, 16-bit surfaces become 32-bit surfaces
+		if dst.Format.BitsPerPixel != 32 || dst.Format.Amask == 0 {
+			t.Errorf("smooth %v: got %d bits per pixel and alpha mask %#x, want a 32-bit surface with alpha",
+				smooth, dst.Format.BitsPerPixel, dst.Format.Amask)
+		}
+
+		// The red pixel stays red, without any magenta at the edge
+		dst.Lock()
+		for x := 0; x < 4; x++ {
+			r, g, b, a := dst.GetRGBA(dst.getPixel(x, 0))
+			switch {
+			case x < 2 && (r != 255 || g != 0 || b != 0 || a != 255):
+				t.Errorf("smooth %v: pixel %d is (%d, %d, %d, %d), want opaque red", smooth, x, r, g, b, a)
+			case x == 2 && a == 255:
+				t.Errorf("smooth %v: pixel 2 is opaque, want a transparent edge", smooth)
+			case x == 3 && a != 0:
+				t.Errorf("smooth %v: pixel 3 has alpha %d, want transparent", smooth, a)
+			case a != 0 && (r != 255 || g != 0 || b != 0):
+				t.Errorf("smooth %v: pixel %d is (%d, %d, %d), want red", smooth, x, r, g, b)
+			}
+		}
+		dst.Unlock()
+
+		dst.Free()
+	}
+}
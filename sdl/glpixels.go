@@ -0,0 +1,76 @@
+package sdl
+
+import "unsafe"
+
+// OpenGL enums returned by GLPixels
+const (
+	glRGBA         = 0x1908 // GL_RGBA
+	glUnsignedByte = 0x1401 // GL_UNSIGNED_BYTE
+)
+
+// Returns the pixels of the surface in a layout OpenGL can upload directly,
+// with the format and type to pass to glTexImage2D (GL_RGBA and
+// GL_UNSIGNED_BYTE) and the size of the image. Rows are tightly packed,
+// so the default GL_UNPACK_ALIGNMENT of 4 works. Returns a nil pointer
+// on error.
+//
+// Surfaces that already store the bytes R, G, B, A (see RGBA8888Masks) and
+// don't need locking (software surfaces without RLE acceleration) are
+// returned as they are. Others are converted to a copy, in which pixels
+// matching the colorkey become transparent and formats without an alpha
+// channel become opaque. The copy belongs to s and stays valid until the next
+// call of GLPixels or until s is freed, so the pointer can be used for the
+// upload right away but must not be kept.
+func (s *Surface) GLPixels() (pixels unsafe.Pointer, format, typ int, w, h int) {
+	f := s.Format
+	w, h = int(s.W), int(s.H)
+
+	r, g, b, a := RGBA8888Masks()
+	if f.BitsPerPixel == 32 && f.Rmask == r && f.Gmask == g && f.Bmask == b && f.Amask == a &&
+		int(s.Pitch) == 4*w && !s.mustLock() {
+		return s.Pixels, glRGBA, glUnsignedByte, w, h
+	}
+
+	c := CreateSurface(SWSURFACE, w, h, PixelFormatRGBA8888).owned()
+	if c == nil {
+		return nil, 0, 0, 0, 0
+	}
+
+	if s.Lock() != 0 {
+		c.Free()
+		return nil, 0, 0, 0, 0
+	}
+	if c.Lock() != 0 {
+		s.Unlock()
+		c.Free()
+		return nil, 0, 0, 0, 0
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pixel := s.getPixel(x, y)
+
+			cr, cg, cb, ca := decodeRGBA(f, pixel)
+			switch {
+			case s.Flags&SRCCOLORKEY != 0 && pixel == f.Colorkey:
+				ca = 0
+			case f.Amask == 0:
+				ca = 0xff
+			}
+
+			c.setPixel(x, y, encodeRGBA(c.Format, cr, cg, cb, ca))
+		}
+	}
+	c.Unlock()
+	s.Unlock()
+
+	s.mutex.Lock()
+	old := s.glPixels
+	s.glPixels = c
+	s.mutex.Unlock()
+
+	if old != nil {
+		old.Free()
+	}
+
+	return c.Pixels, glRGBA, glUnsignedByte, w, h
+}
@@ -0,0 +1,69 @@
+package sdl
+
+import (
+	"errors"
+	"os"
+)
+
+// Hints recognized by SetHint. SDL 1.2 reads its configuration from these
+// environment variables; other names are passed through to the environment
+// as well, since the drivers read many more of them.
+const (
+	// Name of the video driver, such as "x11", "directx", "quartz" or "dummy".
+	// Must be set before the video subsystem is initialized.
+	HINT_VIDEODRIVER = "SDL_VIDEODRIVER"
+
+	// Name of the audio driver, such as "alsa", "pulse", "dsound" or "dummy".
+	// Must be set before the audio subsystem is initialized.
+	HINT_AUDIODRIVER = "SDL_AUDIODRIVER"
+
+	// Device file of the (first) joystick on Linux, such as "/dev/input/js0".
+	// Must be set before the joystick subsystem is initialized.
+	HINT_JOYSTICK_DEVICE = "SDL_JOYSTICK_DEVICE"
+
+	// "1" keeps the screensaver enabled while the application runs.
+	// Must be set before the video subsystem is initialized.
+	HINT_VIDEO_ALLOW_SCREENSAVER = "SDL_VIDEO_ALLOW_SCREENSAVER"
+
+	// "1" centers the window, "x,y" places it (see CenterWindow and
+	// SetWindowPosition). Read by SetVideoMode.
+	HINT_VIDEO_CENTERED   = "SDL_VIDEO_CENTERED"
+	HINT_VIDEO_WINDOW_POS = "SDL_VIDEO_WINDOW_POS"
+
+	// Path of the OpenGL library to load. Read by SetVideoMode with OPENGL.
+	HINT_VIDEO_GL_DRIVER = "SDL_VIDEO_GL_DRIVER"
+
+	// "0" makes the X11 driver report absolute positions while the input is
+	// grabbed and the cursor hidden, instead of warping the cursor to
+	// compute relative motion. Read when the grab or cursor changes.
+	HINT_MOUSE_RELATIVE = "SDL_MOUSE_RELATIVE"
+
+	// "1" makes the X11 driver use DGA for mouse input while grabbed.
+	// Must be set before the video subsystem is initialized.
+	HINT_VIDEO_X11_DGAMOUSE = "SDL_VIDEO_X11_DGAMOUSE"
+)
+
+// The subsystem that reads a hint when it is initialized, so the hint
+// has no effect once the subsystem is running.
+var hintSubsystems = map[string]uint32{
+	HINT_VIDEODRIVER:             INIT_VIDEO,
+	HINT_AUDIODRIVER:             INIT_AUDIO,
+	HINT_JOYSTICK_DEVICE:         INIT_JOYSTICK,
+	HINT_VIDEO_ALLOW_SCREENSAVER: INIT_VIDEO,
+	HINT_VIDEO_X11_DGAMOUSE:      INIT_VIDEO,
+}
+
+// Sets a configuration hint, see the HINT_* constants for the recognized names.
+//
+// SDL 1.2 has no hint API and reads its configuration from environment
+// variables, so this sets the environment variable of the same name.
+// Returns an error if the hint is read when a subsystem is initialized
+// and that subsystem is already running, since the hint would be ignored.
+// SDL 2 hints like render quality have no equivalent in SDL 1.2.
+func SetHint(name, value string) error {
+	if flag, ok := hintSubsystems[name]; ok && WasInit(flag) != 0 {
+		return errors.New("SetHint: " + name + " must be set before Init")
+	}
+
+	return os.Setenv(name, value)
+}
@@ -0,0 +1,129 @@
+package sdl
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"os"
+)
+
+// Creates a 32-bit surface with an alpha channel holding a copy of the image.
+// Returns nil on error.
+func FromImage(img image.Image) *Surface {
+	b := img.Bounds()
+	r, g, bl, a := RGBA8888Masks()
+
+	s := CreateRGBSurface(SWSURFACE, b.Dx(), b.Dy(), 32, r, g, bl, a)
+	if s == nil {
+		return nil
+	}
+
+	// With the RGBA8888 masks, a row of the surface has the layout of a row
+	// of image.NRGBA (SDL uses straight alpha, like NRGBA)
+	status := s.EachRow(func(y int, row []byte) {
+		if n, ok := img.(*image.NRGBA); ok {
+			i := n.PixOffset(b.Min.X, b.Min.Y+y)
+			copy(row, n.Pix[i:i+len(row)])
+			return
+		}
+
+		for x := 0; x < b.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			row[4*x], row[4*x+1], row[4*x+2], row[4*x+3] = c.R, c.G, c.B, c.A
+		}
+	})
+	if status != 0 {
+		s.Free()
+		return nil
+	}
+
+	return s
+}
+
+// Loads all frames of an animated GIF, together with the delay after each
+// frame in milliseconds.
+//
+// The frames are composited as the GIF specifies, taking the disposal method
+// of each frame into account, so every returned surface is a complete
+// picture of the size of the animation (see FromImage).
+func LoadGIFFrames(path string) ([]*Surface, []int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	canvas := image.NewNRGBA(bounds)
+	var previous *image.NRGBA
+
+	frames := make([]*Surface, 0, len(g.Image))
+	delays := make([]int, 0, len(g.Image))
+
+	for i, frame := range g.Image {
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewNRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		s := FromImage(canvas)
+		if s == nil {
+			for _, f := range frames {
+				f.Free()
+			}
+			return nil, nil, errors.New(GetError())
+		}
+		frames = append(frames, s)
+		delays = append(delays, 10*g.Delay[i])
+
+		// Prepare the canvas for the next frame
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+
+	return frames, delays, nil
+}
+
+// Loads an image like Load and converts it to the display format, so that
+// blitting it to the screen needs no conversion each time; images with
+// an alpha channel are converted with DisplayFormatAlpha, the others with
+// DisplayFormat. A video mode must be set. Returns nil on error.
+func LoadOptimized(file string) *Surface {
+	if GetVideoSurface() == nil {
+		SetError("LoadOptimized: no video mode set")
+		return nil
+	}
+
+	loaded := Load(file)
+	if loaded == nil {
+		return nil
+	}
+
+	var optimized *Surface
+	if loaded.Format.Amask != 0 {
+		optimized = loaded.DisplayFormatAlpha()
+	} else {
+		optimized = loaded.DisplayFormat()
+	}
+	loaded.Free()
+
+	return optimized
+}
@@ -0,0 +1,64 @@
+package sdl
+
+// How the raw value of a joystick axis is processed, see JoystickProfile.
+type AxisProfile struct {
+	Deadzone    float64 `json:"deadzone"`    // Values closer to the center than this (0 to 1) are 0
+	Invert      bool    `json:"invert"`      // Reverse the direction of the axis
+	Sensitivity float64 `json:"sensitivity"` // Scale factor, 0 means 1
+}
+
+// The calibration of a joystick: deadzone, inversion and sensitivity of its
+// axes. Profiles can be saved and loaded with encoding/json.
+type JoystickProfile struct {
+	Default AxisProfile         `json:"default"` // For axes without their own profile
+	Axes    map[int]AxisProfile `json:"axes"`    // By axis index
+}
+
+// Returns the position of each axis of the joystick, processed according
+// to the profile, in the range -1 to 1. The map is indexed by axis.
+//
+// Outside the deadzone, the value is rescaled so that it still starts
+// at 0 at the edge of the deadzone and reaches 1 at the end of the axis.
+func (p *JoystickProfile) Apply(j *Joystick) map[int]float64 {
+	values := make(map[int]float64)
+
+	for axis := 0; axis < j.NumAxes(); axis++ {
+		profile, ok := p.Axes[axis]
+		if !ok {
+			profile = p.Default
+		}
+		values[axis] = profile.apply(j.GetAxis(axis))
+	}
+
+	return values
+}
+
+func (p AxisProfile) apply(raw int16) float64 {
+	v := float64(raw) / 32767
+	if v < -1 {
+		v = -1
+	}
+
+	magnitude := v
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+	if magnitude <= p.Deadzone {
+		return 0
+	}
+	if p.Deadzone < 1 {
+		magnitude = (magnitude - p.Deadzone) / (1 - p.Deadzone)
+	}
+
+	if p.Sensitivity != 0 {
+		magnitude *= p.Sensitivity
+	}
+	if magnitude > 1 {
+		magnitude = 1
+	}
+
+	if (v < 0) != p.Invert {
+		return -magnitude
+	}
+	return magnitude
+}
@@ -0,0 +1,139 @@
+package sdl
+
+import "sync"
+
+// Characters produced by the keys of a US keyboard while shift is held.
+var usShifted = map[Key]rune{
+	K_1: '!', K_2: '@', K_3: '#', K_4: '$', K_5: '%',
+	K_6: '^', K_7: '&', K_8: '*', K_9: '(', K_0: ')',
+	K_BACKQUOTE: '~', K_MINUS: '_', K_EQUALS: '+',
+	K_LEFTBRACKET: '{', K_RIGHTBRACKET: '}', K_BACKSLASH: '|',
+	K_SEMICOLON: ':', K_QUOTE: '"', K_COMMA: '<', K_PERIOD: '>', K_SLASH: '?',
+}
+
+// Characters produced by the keypad.
+var keypadRunes = map[Key]rune{
+	K_KP_PERIOD: '.', K_KP_DIVIDE: '/', K_KP_MULTIPLY: '*',
+	K_KP_MINUS: '-', K_KP_PLUS: '+', K_KP_EQUALS: '=',
+}
+
+// Returns the character that the key produces on a US keyboard with the given
+// modifiers, or 0 if the key doesn't produce a printable character.
+//
+// This is a fallback for platforms where UNICODE translation (see EnableUNICODE)
+// leaves the Unicode field of key events zero. It knows only the US layout,
+// so use the Unicode field whenever it is set.
+func KeyToRune(key Key, mod Mod) rune {
+	shift := mod&(KMOD_LSHIFT|KMOD_RSHIFT) != 0
+
+	switch {
+	case key >= K_a && key <= K_z:
+		if shift != (mod&KMOD_CAPS != 0) {
+			return rune(key-K_a) + 'A'
+		}
+		return rune(key)
+
+	case key >= K_KP0 && key <= K_KP9:
+		if mod&KMOD_NUM != 0 {
+			return rune(key-K_KP0) + '0'
+		}
+		return 0
+	}
+
+	if r, ok := keypadRunes[key]; ok {
+		return r
+	}
+
+	if shift {
+		if r, ok := usShifted[key]; ok {
+			return r
+		}
+	}
+
+	// The remaining printable keys are named after their ASCII code
+	if key >= K_SPACE && key < K_DELETE {
+		return rune(key)
+	}
+
+	return 0
+}
+
+// Keys seen in keyboard events, indexed by scancode
+var scancodeKeys struct {
+	sync.Mutex
+	keys [256]Key
+}
+
+// Remembers which key a scancode produced, see ScancodeToKey.
+func learnScancode(keysym *Keysym) {
+	scancodeKeys.Lock()
+	scancodeKeys.keys[keysym.Scancode] = Key(keysym.Sym)
+	scancodeKeys.Unlock()
+}
+
+// Returns the key (keysym) produced by the physical key with the given
+// scancode, or K_UNKNOWN if it isn't known.
+//
+// Scancodes identify physical keys, but SDL 1.2 leaves them to the platform
+// and has no way to translate them. The translation is therefore learned
+// from the keyboard events (Keysym.Scancode and Keysym.Sym), so a key is
+// only known after it was pressed or released at least once.
+func ScancodeToKey(code uint8) Key {
+	scancodeKeys.Lock()
+	key := scancodeKeys.keys[code]
+	scancodeKeys.Unlock()
+	return key
+}
+
+// Returns the index of the key in the slices returned by GetKeyState
+// and KeyStateSnapshot, or -1 if the key has no entry there.
+//
+// In SDL 1.2 the keyboard state is indexed by the keysym itself (not by
+// scancode), so for valid keys the index is simply the value of the key.
+func KeyToIndex(key Key) int {
+	if key <= K_UNKNOWN || key >= K_LAST {
+		return -1
+	}
+	return int(key)
+}
+
+// The result of AllKeyNames, computed once the video subsystem is initialized
+var keyNames struct {
+	sync.Mutex
+	names map[Key]string
+}
+
+// Returns the names of all keys known to SDL (see GetKeyName), for example
+// to list the keys in a key binding screen. Keys without a name are left out.
+//
+// SDL knows the names only once the video subsystem is initialized; before,
+// AllKeyNames sets the SDL error and returns nil. From then on, the names are
+// looked up at the first call and kept, since they don't change while the
+// program runs (SDL 1.2 doesn't report switches of the keyboard layout).
+// The returned map is a copy and may be modified.
+func AllKeyNames() map[Key]string {
+	lockGlobal()
+	initialized := videoInitialized("AllKeyNames")
+	unlockGlobal()
+	if !initialized {
+		return nil
+	}
+
+	keyNames.Lock()
+	if keyNames.names == nil {
+		keyNames.names = make(map[Key]string)
+		for key := Key(K_FIRST + 1); key < K_LAST; key++ {
+			if name := GetKeyName(key); name != "" && name != "unknown key" {
+				keyNames.names[key] = name
+			}
+		}
+	}
+
+	names := make(map[Key]string, len(keyNames.names))
+	for key, name := range keyNames.names {
+		names[key] = name
+	}
+	keyNames.Unlock()
+
+	return names
+}
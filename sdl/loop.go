@@ -0,0 +1,78 @@
+package sdl
+
+import "runtime"
+
+// Runs f with the calling goroutine locked to its OS thread.
+//
+// Many platforms require the video functions to be called from one thread,
+// and some (notably OS X) require it to be the main thread of the process.
+// Call Main from the main function and do all video work inside f.
+// To be sure the main goroutine is still on the main thread at that point,
+// also call runtime.LockOSThread from an init function of package main.
+func Main(f func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	f()
+}
+
+// Calls update once per frame until it returns false, then returns.
+//
+// The update function receives the time elapsed since the previous frame
+// in seconds. Before each update, Run pumps the events (see PumpEvents)
+// from the goroutine calling it, which should be the one that set the video
+// mode (see Main). After each update, the current video surface is presented
+// (see Surface.Present).
+//
+// The pumped events arrive on the Events channel, update can receive them
+// without blocking by using a select statement with a default case.
+// In single-threaded mode (see SetSingleThreaded), update takes them
+// off the queue with PollEvent instead.
+//
+// Run doesn't limit the frame rate, use gfx.FPSmanager or Delay for that.
+func Run(update func(dt float64) bool) {
+	last := GetTicks()
+
+	for {
+		now := GetTicks()
+		dt := float64(now-last) / 1000
+		last = now
+
+		PumpEvents()
+		notifyResize()
+
+		if !update(dt) {
+			return
+		}
+
+		if screen := GetVideoSurface(); screen != nil {
+			screen.Present()
+		}
+	}
+}
+
+// Frame delays recommended by AutoThrottle, in milliseconds
+const (
+	ThrottleFocused   = 16  // About 60 frames per second
+	ThrottleUnfocused = 100 // 10 frames per second
+	ThrottlePaused    = 250 // How often to check whether a minimized window came back
+)
+
+// Returns how long to wait before the next frame, given the application
+// state from GetAppState. Call it once per frame and pass the delay to Delay,
+// so that an application in the background doesn't waste CPU time and battery.
+//
+// A window with input focus gets ThrottleFocused, a visible window without it
+// ThrottleUnfocused. A minimized window (APPACTIVE cleared) doesn't need
+// to be drawn at all: paused is true and the delay is ThrottlePaused.
+// Skip updating and drawing while paused, but keep handling events,
+// an ActiveEvent arrives when the window is restored.
+func AutoThrottle(state uint8) (delay uint32, paused bool) {
+	switch {
+	case state&APPACTIVE == 0:
+		return ThrottlePaused, true
+	case state&APPINPUTFOCUS == 0:
+		return ThrottleUnfocused, false
+	}
+	return ThrottleFocused, false
+}
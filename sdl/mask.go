@@ -0,0 +1,90 @@
+package sdl
+
+// Returns the alpha channel of a 32-bit surface as an 8-bit grayscale surface
+// of the same size, in which pixel values are the alpha values (0 is black,
+// transparent; 255 is white, opaque). Returns nil on error.
+func (s *Surface) AlphaMask() *Surface {
+	f := s.Format
+	if f.BytesPerPixel != 4 || f.Amask == 0 {
+		SetError("AlphaMask: surface must have 32 bits per pixel and an alpha channel")
+		return nil
+	}
+
+	w, h := int(s.W), int(s.H)
+	mask := CreateSurface(SWSURFACE, w, h, PixelFormatIndex8)
+	if mask == nil {
+		return nil
+	}
+	mask.SetColors(grayPalette(), 0)
+
+	if s.Lock() != 0 {
+		mask.Free()
+		return nil
+	}
+	if mask.Lock() != 0 {
+		s.Unlock()
+		mask.Free()
+		return nil
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			_, _, _, a := decodeRGBA(f, s.getPixel(x, y))
+			mask.setPixel(x, y, uint32(a))
+		}
+	}
+
+	mask.Unlock()
+	s.Unlock()
+
+	return mask
+}
+
+// Replaces the alpha channel of a 32-bit surface with the brightness of the
+// mask, a grayscale surface of the same size such as the one returned by
+// AlphaMask. The red component of the mask pixels is used, which is the
+// brightness for gray pixels in any format. Returns 0 on success, -1 on error.
+func (s *Surface) SetAlphaFromMask(mask *Surface) int {
+	f, mf := s.Format, mask.Format
+	if f.BytesPerPixel != 4 || f.Amask == 0 {
+		SetError("SetAlphaFromMask: surface must have 32 bits per pixel and an alpha channel")
+		return -1
+	}
+	if mask.W != s.W || mask.H != s.H {
+		SetError("SetAlphaFromMask: the mask must have the size of the surface")
+		return -1
+	}
+
+	if s.Lock() != 0 {
+		return -1
+	}
+	if mask != s && mask.Lock() != 0 {
+		s.Unlock()
+		return -1
+	}
+	s.MarkModified()
+
+	for y := 0; y < int(s.H); y++ {
+		for x := 0; x < int(s.W); x++ {
+			a, _, _, _ := decodeRGBA(mf, mask.getPixel(x, y))
+			pixel := s.getPixel(x, y)
+			s.setPixel(x, y, pixel&^f.Amask|uint32(a>>f.Aloss)<<f.Ashift&f.Amask)
+		}
+	}
+
+	if mask != s {
+		mask.Unlock()
+	}
+	s.Unlock()
+
+	return 0
+}
+
+// Returns a palette in which entry i is the gray (i, i, i).
+func grayPalette() []Color {
+	colors := make([]Color, 256)
+	for i := range colors {
+		colors[i] = Color{R: uint8(i), G: uint8(i), B: uint8(i)}
+	}
+	return colors
+}
@@ -0,0 +1,141 @@
+package sdl
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Number and pixel memory of the surfaces counted by account, see SurfaceMemoryStats
+var surfaceStats struct {
+	count, bytes                 int64
+	externalCount, externalBytes int64
+}
+
+// Counts a surface created by the package until it is freed.
+// external means that the pixels aren't owned by SDL (CreateRGBSurfaceFrom).
+// Returns s, which may be nil.
+func (s *Surface) account(external bool) *Surface {
+	if s == nil {
+		return nil
+	}
+
+	s.accountedBytes = int64(s.Pitch) * int64(s.H)
+	s.accountedExternal = external
+
+	if external {
+		atomic.AddInt64(&surfaceStats.externalCount, 1)
+		atomic.AddInt64(&surfaceStats.externalBytes, s.accountedBytes)
+	} else {
+		atomic.AddInt64(&surfaceStats.count, 1)
+		atomic.AddInt64(&surfaceStats.bytes, s.accountedBytes)
+	}
+	s.accounted = true
+
+	tracking.Lock()
+	tracking.live[s] = struct{}{}
+	if tracking.sites != nil {
+		tracking.sites[s] = fmt.Sprintf("%dx%d surface (%d bytes) created at:\n%s", s.W, s.H, s.accountedBytes, debug.Stack())
+	}
+	tracking.Unlock()
+
+	return s
+}
+
+// Marks a surface as owned by another surface that frees it, like the copies
+// made by DisplayFormatCached and GLPixels. It stays counted, but Shutdown
+// and ReportLeakedSurfaces leave it to its owner. Returns s, which may be nil.
+func (s *Surface) owned() *Surface {
+	if s == nil {
+		return nil
+	}
+
+	tracking.Lock()
+	delete(tracking.live, s)
+	delete(tracking.sites, s)
+	tracking.Unlock()
+
+	return s
+}
+
+// Removes a surface from the counts, when it is freed.
+// Must be called with the mutex of the surface locked.
+func (s *Surface) unaccount() {
+	if !s.accounted {
+		return
+	}
+	s.accounted = false
+
+	tracking.Lock()
+	delete(tracking.live, s)
+	delete(tracking.sites, s)
+	tracking.Unlock()
+
+	if s.accountedExternal {
+		atomic.AddInt64(&surfaceStats.externalCount, -1)
+		atomic.AddInt64(&surfaceStats.externalBytes, -s.accountedBytes)
+	} else {
+		atomic.AddInt64(&surfaceStats.count, -1)
+		atomic.AddInt64(&surfaceStats.bytes, -s.accountedBytes)
+	}
+}
+
+// Returns the number of surfaces that have been created and not freed yet,
+// and the size of their pixel data in bytes (Pitch*H, see ByteSize).
+// A count that keeps growing during a game points to a surface leak.
+//
+// Counted are the surfaces created by CreateRGBSurface (and the functions
+// based on it, like CreateSurface), Load, LoadBMP, DisplayFormat,
+// DisplayFormatAlpha, Clone and Zoom. The screen surface and surfaces
+// created by other packages, like ttf, aren't counted. The pixels of
+// surfaces created by CreateRGBSurfaceFrom belong to the caller,
+// they are counted separately by ExternalSurfaceMemoryStats.
+func SurfaceMemoryStats() (count int, bytes int64) {
+	return int(atomic.LoadInt64(&surfaceStats.count)), atomic.LoadInt64(&surfaceStats.bytes)
+}
+
+// Like SurfaceMemoryStats, but for the surfaces created by CreateRGBSurfaceFrom,
+// whose pixels are owned by the caller.
+func ExternalSurfaceMemoryStats() (count int, bytes int64) {
+	return int(atomic.LoadInt64(&surfaceStats.externalCount)), atomic.LoadInt64(&surfaceStats.externalBytes)
+}
+
+// The surfaces counted by account, and where they were created
+var tracking = struct {
+	sync.Mutex
+	live  map[*Surface]struct{} // Freed by Shutdown, without the owned surfaces
+	sites map[*Surface]string   // See EnableSurfaceTracking, nil if tracking is off
+}{live: make(map[*Surface]struct{})}
+
+// Turns the tracking of surface leaks on or off. While it is on, a stack trace
+// is recorded for every surface counted by SurfaceMemoryStats (or
+// ExternalSurfaceMemoryStats) when it is created, and dropped when the surface
+// is freed, so ReportLeakedSurfaces can tell where the surfaces that were
+// never freed came from. Recording stack traces is slow, this is meant for
+// tests and debugging. Turning tracking off forgets the recorded surfaces.
+func EnableSurfaceTracking(enabled bool) {
+	tracking.Lock()
+	if enabled && tracking.sites == nil {
+		tracking.sites = make(map[*Surface]string)
+	} else if !enabled {
+		tracking.sites = nil
+	}
+	tracking.Unlock()
+}
+
+// Returns a description, with the stack trace of its creation, of every
+// surface created while tracking was on (see EnableSurfaceTracking) and not
+// freed yet. Call it in the teardown of a test to find surface leaks.
+func ReportLeakedSurfaces() []string {
+	tracking.Lock()
+	leaks := make([]string, 0, len(tracking.sites))
+	for _, site := range tracking.sites {
+		leaks = append(leaks, site)
+	}
+	tracking.Unlock()
+
+	sort.Strings(leaks)
+	return leaks
+}
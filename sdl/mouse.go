@@ -0,0 +1,253 @@
+package sdl
+
+import (
+	"math"
+	"sync"
+)
+
+// Counts repeated clicks (double clicks, triple clicks, ...),
+// which SDL 1.2 doesn't report.
+//
+// A press continues the current series of clicks if it is made with the same
+// button, no later than Interval milliseconds after the previous press, and
+// no farther than Tolerance pixels (horizontally and vertically) from it.
+type ClickDetector struct {
+	Interval  uint32 // Maximum time between presses, in milliseconds
+	Tolerance int    // Maximum distance between presses, in pixels
+
+	clicks int
+	button uint8
+	ticks  uint32
+	x, y   int
+}
+
+// Creates a click detector with the usual desktop settings
+// (500 ms, 4 pixels).
+func NewClickDetector() *ClickDetector {
+	return &ClickDetector{Interval: 500, Tolerance: 4}
+}
+
+// Processes a mouse button event. For a MOUSEBUTTONDOWN event, returns the number
+// of the click in the current series (1 for a single click, 2 for a double click
+// and so on). For other events, returns 0.
+func (d *ClickDetector) Process(ev *MouseButtonEvent) (clicks int) {
+	if ev.Type != MOUSEBUTTONDOWN {
+		return 0
+	}
+
+	now := GetTicks()
+	x, y := int(ev.X), int(ev.Y)
+
+	if d.clicks > 0 && ev.Button == d.button && now-d.ticks <= d.Interval &&
+		abs(x-d.x) <= d.Tolerance && abs(y-d.y) <= d.Tolerance {
+		d.clicks++
+	} else {
+		d.clicks = 1
+	}
+
+	d.button = ev.Button
+	d.ticks = now
+	d.x, d.y = x, y
+
+	return d.clicks
+}
+
+// Forgets the current series of clicks.
+func (d *ClickDetector) Reset() {
+	d.clicks = 0
+}
+
+// Kinds of gestures recognized by GestureRecognizer.
+const (
+	GestureTap = iota
+	GestureSwipeLeft
+	GestureSwipeRight
+	GestureSwipeUp
+	GestureSwipeDown
+)
+
+// A gesture made with the left mouse button (or a touchscreen).
+type Gesture struct {
+	Kind     int     // One of the Gesture* constants
+	X, Y     int     // Where the button was pressed
+	DX, DY   int     // Distance moved until the button was released
+	Duration uint32  // Time between press and release, in milliseconds
+	Velocity float64 // Average speed, in pixels per second
+}
+
+// Turns presses, movements and releases of the left mouse button into taps
+// and swipes, which is useful for touchscreen (kiosk) applications, since
+// SDL 1.2 reports touches as mouse events.
+//
+// A press and release is a tap if the pointer moved at most TapDistance
+// pixels within TapTime milliseconds. It is a swipe if the pointer moved
+// at least SwipeDistance pixels at an average speed of at least
+// SwipeVelocity pixels per second. The direction of a swipe is the axis
+// along which the pointer moved the most.
+type GestureRecognizer struct {
+	TapDistance   int
+	TapTime       uint32
+	SwipeDistance int
+	SwipeVelocity float64
+
+	OnGesture func(g Gesture) // Called for each recognized gesture
+
+	down  bool
+	x, y  int
+	lastX int
+	lastY int
+	ticks uint32
+}
+
+// Creates a gesture recognizer with the usual thresholds (taps within
+// 10 pixels and 300 ms, swipes of 50 pixels at 300 pixels per second).
+func NewGestureRecognizer(onGesture func(g Gesture)) *GestureRecognizer {
+	return &GestureRecognizer{
+		TapDistance:   10,
+		TapTime:       300,
+		SwipeDistance: 50,
+		SwipeVelocity: 300,
+		OnGesture:     onGesture,
+	}
+}
+
+// Processes an event received from Events (or a pointer to one).
+// Events other than mouse button and motion events are ignored.
+func (r *GestureRecognizer) Process(event interface{}) {
+	switch e := event.(type) {
+	case *MouseButtonEvent:
+		r.Process(*e)
+	case *MouseMotionEvent:
+		r.Process(*e)
+
+	case MouseMotionEvent:
+		if r.down {
+			r.lastX, r.lastY = int(e.X), int(e.Y)
+		}
+
+	case MouseButtonEvent:
+		if e.Button != BUTTON_LEFT {
+			return
+		}
+
+		if e.Type == MOUSEBUTTONDOWN {
+			r.down = true
+			r.x, r.y = int(e.X), int(e.Y)
+			r.lastX, r.lastY = r.x, r.y
+			r.ticks = GetTicks()
+		} else if r.down {
+			r.down = false
+			r.lastX, r.lastY = int(e.X), int(e.Y)
+			r.recognize(GetTicks() - r.ticks)
+		}
+	}
+}
+
+func (r *GestureRecognizer) recognize(duration uint32) {
+	g := Gesture{X: r.x, Y: r.y, DX: r.lastX - r.x, DY: r.lastY - r.y, Duration: duration}
+
+	distance := math.Hypot(float64(g.DX), float64(g.DY))
+	if duration > 0 {
+		g.Velocity = distance * 1000 / float64(duration)
+	}
+
+	switch {
+	case distance <= float64(r.TapDistance) && duration <= r.TapTime:
+		g.Kind = GestureTap
+
+	case distance >= float64(r.SwipeDistance) && (duration == 0 || g.Velocity >= r.SwipeVelocity):
+		switch {
+		case abs(g.DX) >= abs(g.DY) && g.DX < 0:
+			g.Kind = GestureSwipeLeft
+		case abs(g.DX) >= abs(g.DY):
+			g.Kind = GestureSwipeRight
+		case g.DY < 0:
+			g.Kind = GestureSwipeUp
+		default:
+			g.Kind = GestureSwipeDown
+		}
+
+	default:
+		return
+	}
+
+	if r.OnGesture != nil {
+		r.OnGesture(g)
+	}
+}
+
+// Returns the mask of a mouse button in the state returned by GetMouseState,
+// like the SDL_BUTTON macro. For example BUTTON(BUTTON_RIGHT) == BUTTON_RMASK.
+func BUTTON(button int) uint8 {
+	return 1 << uint(button-1)
+}
+
+// Reports whether a mouse button is held down in the state returned by
+// GetMouseState (or GetRelativeMouseState), for example
+// MouseButtonDown(GetMouseState(nil, nil), BUTTON_RIGHT).
+//
+// SDL 1.2 reports the mouse wheel as the buttons BUTTON_WHEELUP and
+// BUTTON_WHEELDOWN, which are pressed and released right away, so they are
+// almost never seen held down here. Use the MOUSEBUTTONDOWN events for the wheel.
+func MouseButtonDown(state uint8, button int) bool {
+	return button >= 1 && button <= 8 && state&BUTTON(button) != 0
+}
+
+var mouseConfinement struct {
+	sync.Mutex
+	rect *Rect // nil if the mouse isn't confined
+}
+
+// Keeps the mouse cursor inside the rectangle (in window coordinates),
+// for example for edge scrolling in a windowed game; nil ends the confinement.
+//
+// This isn't a clipping done by the operating system. Each time the events
+// are polled (every few milliseconds, see Events), a cursor that has left
+// the rectangle is warped back to its nearest edge (see WarpMouse), so it
+// may be seen outside for a moment. Nothing is done while the window has
+// neither mouse nor input focus, and a fast movement can still take the
+// cursor out of the window; use WM_GrabInput to prevent that.
+// In single-threaded mode (see SetSingleThreaded) events aren't polled in the
+// background, call ConfineMouse with the same rectangle once per frame instead.
+func ConfineMouse(rect *Rect) {
+	mouseConfinement.Lock()
+	if rect != nil {
+		r := *rect
+		mouseConfinement.rect = &r
+	} else {
+		mouseConfinement.rect = nil
+	}
+	mouseConfinement.Unlock()
+
+	applyMouseConfinement()
+}
+
+// Warps the mouse cursor back into the rectangle set by ConfineMouse.
+func applyMouseConfinement() {
+	mouseConfinement.Lock()
+	rect := mouseConfinement.rect
+	mouseConfinement.Unlock()
+
+	if rect == nil || rect.W == 0 || rect.H == 0 {
+		return
+	}
+	if GetAppState()&(APPMOUSEFOCUS|APPINPUTFOCUS) != APPMOUSEFOCUS|APPINPUTFOCUS {
+		return
+	}
+
+	var x, y int
+	GetMouseState(&x, &y)
+
+	nx := clampInt(x, int(rect.X), int(rect.X)+int(rect.W)-1)
+	ny := clampInt(y, int(rect.Y), int(rect.Y)+int(rect.H)-1)
+	if nx != x || ny != y {
+		WarpMouse(nx, ny)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
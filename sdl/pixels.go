@@ -0,0 +1,377 @@
+package sdl
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Reads the pixel at (x, y). Works for 1 to 4 bytes per pixel.
+// The surface must be locked and the coordinates must be within the surface.
+func (s *Surface) getPixel(x, y int) uint32 {
+	bpp := int(s.Format.BytesPerPixel)
+	p := unsafe.Pointer(uintptr(s.Pixels) + uintptr(y*int(s.Pitch)+x*bpp))
+
+	switch bpp {
+	case 1:
+		return uint32(*(*uint8)(p))
+	case 2:
+		return uint32(*(*uint16)(p))
+	case 3:
+		b := (*[3]uint8)(p)
+		if BYTEORDER() == BIG_ENDIAN {
+			return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+		}
+		return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+	}
+
+	return *(*uint32)(p)
+}
+
+// Writes the pixel at (x, y). Works for 1 to 4 bytes per pixel.
+// The surface must be locked and the coordinates must be within the surface.
+func (s *Surface) setPixel(x, y int, pixel uint32) {
+	bpp := int(s.Format.BytesPerPixel)
+	p := unsafe.Pointer(uintptr(s.Pixels) + uintptr(y*int(s.Pitch)+x*bpp))
+
+	switch bpp {
+	case 1:
+		*(*uint8)(p) = uint8(pixel)
+	case 2:
+		*(*uint16)(p) = uint16(pixel)
+	case 3:
+		b := (*[3]uint8)(p)
+		if BYTEORDER() == BIG_ENDIAN {
+			b[0], b[1], b[2] = uint8(pixel>>16), uint8(pixel>>8), uint8(pixel)
+		} else {
+			b[0], b[1], b[2] = uint8(pixel), uint8(pixel>>8), uint8(pixel>>16)
+		}
+	default:
+		*(*uint32)(p) = pixel
+	}
+}
+
+// Returns the colors of a palette as a slice aliasing the palette.
+func paletteColors(palette *Palette) []Color {
+	n := int(palette.Ncolors)
+	header := reflect.SliceHeader{uintptr(unsafe.Pointer(palette.Colors)), n, n}
+	return *(*[]Color)(unsafe.Pointer(&header))
+}
+
+// Returns a copy of the surface in which every pixel value that is a key of
+// the mapping is replaced by the corresponding value. Keys and values are pixels
+// in the format of the surface (see MapRGBA). Returns nil on error.
+//
+// For 8-bit palettized surfaces the pixels are left alone and the palette
+// of the copy is changed instead, so that entry k shows the color of entry mapping[k].
+// Team colors for sprites are a typical use.
+func (s *Surface) RemapColors(mapping map[uint32]uint32) *Surface {
+	dst := s.Clone()
+	if dst == nil {
+		return nil
+	}
+
+	if s.Format.Palette != nil {
+		src := paletteColors(s.Format.Palette)
+		colors := make([]Color, len(src))
+		copy(colors, src)
+
+		for k, v := range mapping {
+			if k < uint32(len(colors)) && v < uint32(len(colors)) {
+				colors[k] = src[v]
+			}
+		}
+
+		dst.SetColors(colors, 0)
+		return dst
+	}
+
+	if dst.Lock() != 0 {
+		dst.Free()
+		return nil
+	}
+	for y := 0; y < int(dst.H); y++ {
+		for x := 0; x < int(dst.W); x++ {
+			if v, ok := mapping[dst.getPixel(x, y)]; ok {
+				dst.setPixel(x, y, v)
+			}
+		}
+	}
+	dst.Unlock()
+
+	return dst
+}
+
+// Reports whether a pixel of the surface is fully transparent, either because
+// it matches the colorkey (if SRCCOLORKEY is set) or because its alpha is zero
+// (if the format has an alpha channel).
+func (s *Surface) transparent(pixel uint32) bool {
+	if s.Flags&SRCCOLORKEY != 0 && pixel == s.Format.Colorkey {
+		return true
+	}
+	return s.Format.Amask != 0 && pixel&s.Format.Amask == 0
+}
+
+// Returns the smallest rectangle containing all pixels of the surface that
+// are not fully transparent (see SetColorKey and the alpha channel).
+// This is useful for trimming sprites and computing tight collision rectangles.
+// Returns an empty rectangle if the whole surface is transparent
+// or can't be locked.
+func (s *Surface) OpaqueBounds() Rect {
+	x0, y0, x1, y1 := int(s.W), int(s.H), -1, -1
+
+	if s.Lock() != 0 {
+		return Rect{}
+	}
+	for y := 0; y < int(s.H); y++ {
+		for x := 0; x < int(s.W); x++ {
+			if s.transparent(s.getPixel(x, y)) {
+				continue
+			}
+			if x < x0 {
+				x0 = x
+			}
+			if x > x1 {
+				x1 = x
+			}
+			if y < y0 {
+				y0 = y
+			}
+			y1 = y
+		}
+	}
+	s.Unlock()
+
+	if x1 < 0 {
+		return Rect{}
+	}
+
+	return Rect{int16(x0), int16(y0), uint16(x1 - x0 + 1), uint16(y1 - y0 + 1)}
+}
+
+// Returns the area covered by a surface placed at rect. A nil rect places
+// the surface at (0, 0), a zero width or height means the size of the surface.
+func placement(s *Surface, rect *Rect) (x, y, w, h int) {
+	w, h = int(s.W), int(s.H)
+	if rect != nil {
+		x, y = int(rect.X), int(rect.Y)
+		if rect.W != 0 && int(rect.W) < w {
+			w = int(rect.W)
+		}
+		if rect.H != 0 && int(rect.H) < h {
+			h = int(rect.H)
+		}
+	}
+	return
+}
+
+// Reports whether two surfaces, placed at aRect and bRect, have an opaque pixel
+// at the same position (pixel-perfect collision detection).
+// Transparency is determined by the colorkey and the alpha channel,
+// as in OpaqueBounds. A nil rect places the surface at (0, 0).
+//
+// The bounding boxes are checked first, so surfaces that are far apart
+// are rejected without looking at their pixels. Surfaces that can't be
+// locked don't collide.
+func PixelCollision(a *Surface, aRect *Rect, b *Surface, bRect *Rect) bool {
+	ax, ay, aw, ah := placement(a, aRect)
+	bx, by, bw, bh := placement(b, bRect)
+
+	// Intersection of the bounding boxes
+	x0, y0 := ax, ay
+	if bx > x0 {
+		x0 = bx
+	}
+	if by > y0 {
+		y0 = by
+	}
+	x1, y1 := ax+aw, ay+ah
+	if bx+bw < x1 {
+		x1 = bx + bw
+	}
+	if by+bh < y1 {
+		y1 = by + bh
+	}
+	if x0 >= x1 || y0 >= y1 {
+		return false
+	}
+
+	if a.Lock() != 0 {
+		return false
+	}
+	if b != a && b.Lock() != 0 {
+		a.Unlock()
+		return false
+	}
+
+	collision := false
+	for y := y0; y < y1 && !collision; y++ {
+		for x := x0; x < x1; x++ {
+			if !a.transparent(a.getPixel(x-ax, y-ay)) && !b.transparent(b.getPixel(x-bx, y-by)) {
+				collision = true
+				break
+			}
+		}
+	}
+
+	if b != a {
+		b.Unlock()
+	}
+	a.Unlock()
+
+	return collision
+}
+
+// Calls f for each row of the surface, from top to bottom. The row slice
+// holds the W*BytesPerPixel bytes of the row, without the padding up to Pitch,
+// and aliases the pixels of the surface, so writing to it changes the surface.
+//
+// The surface is locked while EachRow runs. The slice is only valid
+// during the call of f, it must not be kept. Returns 0 on success,
+// or -1 if the surface can't be locked, in which case f isn't called.
+func (s *Surface) EachRow(f func(y int, row []byte)) int {
+	rowSize := int(s.W) * int(s.Format.BytesPerPixel)
+
+	if s.Lock() != 0 {
+		return -1
+	}
+	s.MarkModified() // f may write to the rows
+	for y := 0; y < int(s.H); y++ {
+		f(y, pixelRow(s.Pixels, y*int(s.Pitch), rowSize))
+	}
+	s.Unlock()
+
+	return 0
+}
+
+// Fills a rectangle like FillRect, but clips the rectangle to the surface
+// first and doesn't call SDL at all if nothing is left, so rectangles partly
+// or entirely outside the surface behave predictably. A nil rectangle fills
+// the whole surface. The clip rectangle of the surface still applies.
+// dstrect isn't modified. Returns 0 on success, -1 on error.
+func (dst *Surface) FillRectSafe(dstrect *Rect, color uint32) int {
+	if dstrect == nil {
+		return dst.FillRect(nil, color)
+	}
+
+	x0, y0 := int(dstrect.X), int(dstrect.Y)
+	x1, y1 := x0+int(dstrect.W), y0+int(dstrect.H)
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if x1 > int(dst.W) {
+		x1 = int(dst.W)
+	}
+	if y1 > int(dst.H) {
+		y1 = int(dst.H)
+	}
+	if x0 >= x1 || y0 >= y1 {
+		return 0
+	}
+
+	return dst.FillRect(&Rect{int16(x0), int16(y0), uint16(x1 - x0), uint16(y1 - y0)}, color)
+}
+
+// Replaces the contiguous region of pixels having the color of the pixel
+// at (x, y) with the given color, like the paint bucket of image editors.
+// Pixels are connected horizontally and vertically, not diagonally.
+// The clip rectangle is ignored. Only 32-bit surfaces are supported.
+// Returns 0 on success, -1 on error.
+//
+// This is a scanline fill with an explicit queue of seeds, so even huge
+// regions don't need deep recursion.
+func (s *Surface) FloodFill(x, y int, color uint32) int {
+	if s.Format.BytesPerPixel != 4 {
+		SetError("FloodFill: surface must have 32 bits per pixel")
+		return -1
+	}
+	w, h := int(s.W), int(s.H)
+	if x < 0 || y < 0 || x >= w || y >= h {
+		SetError("FloodFill: seed outside the surface")
+		return -1
+	}
+
+	if s.Lock() != 0 {
+		return -1
+	}
+	s.MarkModified()
+
+	target := s.getPixel(x, y)
+	if target == color {
+		s.Unlock()
+		return 0
+	}
+
+	type seed struct{ x, y int }
+	queue := []seed{{x, y}}
+
+	for len(queue) > 0 {
+		p := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if s.getPixel(p.x, p.y) != target {
+			continue
+		}
+
+		// Extend the span to the left and the right
+		x0, x1 := p.x, p.x
+		for x0 > 0 && s.getPixel(x0-1, p.y) == target {
+			x0--
+		}
+		for x1 < w-1 && s.getPixel(x1+1, p.y) == target {
+			x1++
+		}
+
+		for i := x0; i <= x1; i++ {
+			s.setPixel(i, p.y, color)
+		}
+
+		// Queue one seed per run of matching pixels in the rows above and below
+		for _, ny := range [2]int{p.y - 1, p.y + 1} {
+			if ny < 0 || ny >= h {
+				continue
+			}
+			inRun := false
+			for i := x0; i <= x1; i++ {
+				if s.getPixel(i, ny) != target {
+					inRun = false
+				} else if !inRun {
+					queue = append(queue, seed{i, ny})
+					inRun = true
+				}
+			}
+		}
+	}
+
+	s.Unlock()
+
+	return 0
+}
+
+// Sets the color of the top left pixel as the colorkey, the usual convention
+// of sprite sheets with a magenta (or other) background. The flags are those
+// of SetColorKey, normally SRCCOLORKEY, optionally with RLEACCEL.
+// Returns 0 on success, -1 on error.
+//
+// If the surface has an alpha channel and the top left pixel is already
+// fully transparent, the image brings its own transparency and no colorkey
+// is set.
+func (s *Surface) SetColorKeyAuto(flags uint32) int {
+	if s.W == 0 || s.H == 0 {
+		SetError("SetColorKeyAuto: empty surface")
+		return -1
+	}
+
+	if s.Lock() != 0 {
+		return -1
+	}
+	pixel := s.getPixel(0, 0)
+	s.Unlock()
+
+	if s.Format.Amask != 0 && pixel&s.Format.Amask == 0 {
+		return 0
+	}
+
+	return s.SetColorKey(flags, pixel)
+}
@@ -0,0 +1,75 @@
+package sdl
+
+import "testing"
+
+// Creates a transparent 4x4 sprite with one opaque column.
+func columnSprite(t *testing.T, column int) *Surface {
+	s := CreateSurface(SWSURFACE, 4, 4, PixelFormatRGBA8888)
+	if s == nil {
+		t.Fatal(GetError())
+	}
+
+	s.Lock()
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x == column {
+				s.setPixel(x, y, s.MapRGBA(255, 0, 0, 255))
+			} else {
+				s.setPixel(x, y, s.MapRGBA(255, 0, 0, 0))
+			}
+		}
+	}
+	s.Unlock()
+
+	return s
+}
+
+func TestPixelCollision(t *testing.T) {
+	initHeadless(t)
+
+	left := columnSprite(t, 0)
+	defer left.Free()
+	right := columnSprite(t, 3)
+	defer right.Free()
+
+	tests := []struct {
+		name string
+		x, y int16 // Position of right, left is at (0, 0)
+		want bool
+	}{
+		{"opaque pixels overlap", -3, 0, true},
+		{"only transparent pixels overlap", 0, 0, false},
+		{"opaque pixel over transparent pixel", -2, 1, false},
+		{"bounding boxes touch", 4, 0, false},
+		{"far apart", 100, 100, false},
+	}
+
+	for _, test := range tests {
+		got := PixelCollision(left, nil, right, &Rect{X: test.x, Y: test.y})
+		if got != test.want {
+			t.Errorf("%s: PixelCollision = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPixelCollisionColorKey(t *testing.T) {
+	initHeadless(t)
+
+	a := CreateSurface(SWSURFACE, 2, 2, PixelFormatRGB565)
+	if a == nil {
+		t.Fatal(GetError())
+	}
+	defer a.Free()
+
+	key := a.MapRGB(255, 0, 255)
+	a.FillRect(nil, key)
+	a.FillRect(&Rect{1, 1, 1, 1}, a.MapRGB(0, 0, 0))
+	a.SetColorKey(SRCCOLORKEY, key)
+
+	if PixelCollision(a, nil, a, &Rect{X: 1}) {
+		t.Errorf("colorkeyed pixels collide")
+	}
+	if !PixelCollision(a, nil, a, nil) {
+		t.Errorf("opaque pixel doesn't collide with itself")
+	}
+}
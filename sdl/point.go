@@ -0,0 +1,30 @@
+package sdl
+
+// A point, with coordinates of the same type as those of Rect.
+type Point struct {
+	X int16
+	Y int16
+}
+
+// Returns the point p+q.
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+// Returns the point p-q.
+func (p Point) Sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+// Reports whether the point is inside the rectangle. The left and top edges
+// belong to the rectangle, the right and bottom edges don't.
+func (p Point) In(r Rect) bool {
+	return int(p.X) >= int(r.X) && int(p.X) < int(r.X)+int(r.W) &&
+		int(p.Y) >= int(r.Y) && int(p.Y) < int(r.Y)+int(r.H)
+}
+
+// A size, with dimensions of the same type as those of Rect.
+type Size struct {
+	W uint16
+	H uint16
+}
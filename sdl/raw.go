@@ -0,0 +1,143 @@
+package sdl
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"unsafe"
+)
+
+// Size of the header written by MarshalRaw: width, height, bits per pixel
+// and the four color masks.
+const rawHeaderSize = 4 + 4 + 1 + 4*4
+
+// Largest width and height accepted by UnmarshalRawSurface. SDL 1.2 can't
+// handle larger surfaces anyway (Rect uses 16-bit sizes), and the limit keeps
+// the size computations far from overflowing, even with a 32-bit int.
+const rawMaxSide = 1 << 15
+
+// Encodes the surface in a simple raw format, suitable for sending frames
+// to another process without an image codec.
+//
+// The data starts with a header holding the width and height (uint32),
+// the bits per pixel (uint8) and the R, G, B and A masks (uint32).
+// It is followed by the rows of pixels, without padding between the rows.
+// All values, including the pixels, are stored in little-endian byte order,
+// so the data can be decoded on a machine with a different byte order.
+// The palette of an 8-bit surface is not included.
+func (s *Surface) MarshalRaw() ([]byte, error) {
+	if s.cSurface == nil || s.Format == nil {
+		return nil, errors.New("MarshalRaw: invalid surface")
+	}
+
+	f := s.Format
+	w, h := int(s.W), int(s.H)
+	bpp := int(f.BytesPerPixel)
+	rowSize := w * bpp
+
+	data := make([]byte, rawHeaderSize+rowSize*h)
+	binary.LittleEndian.PutUint32(data[0:], uint32(w))
+	binary.LittleEndian.PutUint32(data[4:], uint32(h))
+	data[8] = f.BitsPerPixel
+	binary.LittleEndian.PutUint32(data[9:], f.Rmask)
+	binary.LittleEndian.PutUint32(data[13:], f.Gmask)
+	binary.LittleEndian.PutUint32(data[17:], f.Bmask)
+	binary.LittleEndian.PutUint32(data[21:], f.Amask)
+
+	if s.Lock() != 0 {
+		return nil, errors.New(GetError())
+	}
+	for y := 0; y < h; y++ {
+		dst := data[rawHeaderSize+y*rowSize : rawHeaderSize+(y+1)*rowSize]
+		if BYTEORDER() == LIL_ENDIAN {
+			copy(dst, pixelRow(s.Pixels, y*int(s.Pitch), rowSize))
+			continue
+		}
+		for x := 0; x < w; x++ {
+			putPixelLE(dst[x*bpp:], bpp, s.getPixel(x, y))
+		}
+	}
+	s.Unlock()
+
+	return data, nil
+}
+
+// Decodes a surface encoded by MarshalRaw. The returned surface owns a copy
+// of the pixels, so data can be reused after the call.
+func UnmarshalRawSurface(data []byte) (*Surface, error) {
+	if len(data) < rawHeaderSize {
+		return nil, errors.New("UnmarshalRawSurface: data too short")
+	}
+
+	width := binary.LittleEndian.Uint32(data[0:])
+	height := binary.LittleEndian.Uint32(data[4:])
+	bitsPerPixel := int(data[8])
+	rmask := binary.LittleEndian.Uint32(data[9:])
+	gmask := binary.LittleEndian.Uint32(data[13:])
+	bmask := binary.LittleEndian.Uint32(data[17:])
+	amask := binary.LittleEndian.Uint32(data[21:])
+
+	bpp := (bitsPerPixel + 7) / 8
+	if bpp < 1 || bpp > 4 {
+		return nil, errors.New("UnmarshalRawSurface: invalid bits per pixel")
+	}
+	if width == 0 || height == 0 || width > rawMaxSide || height > rawMaxSide {
+		return nil, errors.New("UnmarshalRawSurface: invalid size")
+	}
+	w, h := int(width), int(height)
+
+	// Computed in 64 bits, 2^15 * 2^15 * 4 doesn't fit into a 32-bit int
+	if int64(len(data)-rawHeaderSize) != int64(w)*int64(h)*int64(bpp) {
+		return nil, errors.New("UnmarshalRawSurface: invalid size")
+	}
+
+	pixels := make([]byte, w*h*bpp)
+	src := data[rawHeaderSize:]
+	if BYTEORDER() == LIL_ENDIAN {
+		copy(pixels, src)
+	} else {
+		for i := 0; i < len(src); i += bpp {
+			putPixelNative(pixels[i:], bpp, getPixelLE(src[i:], bpp))
+		}
+	}
+
+	s := CreateRGBSurfaceFrom(pixels, w, h, bitsPerPixel, w*bpp, rmask, gmask, bmask, amask)
+	if s == nil {
+		return nil, errors.New(GetError())
+	}
+
+	return s, nil
+}
+
+// Returns n bytes of pixel data starting at the given offset.
+func pixelRow(pixels unsafe.Pointer, offset, n int) []byte {
+	header := reflect.SliceHeader{uintptr(pixels) + uintptr(offset), n, n}
+	return *(*[]byte)(unsafe.Pointer(&header))
+}
+
+// Stores the lowest bpp bytes of a pixel in little-endian byte order.
+func putPixelLE(b []byte, bpp int, pixel uint32) {
+	for i := 0; i < bpp; i++ {
+		b[i] = uint8(pixel >> uint(8*i))
+	}
+}
+
+// Loads a pixel of bpp bytes stored in little-endian byte order.
+func getPixelLE(b []byte, bpp int) (pixel uint32) {
+	for i := 0; i < bpp; i++ {
+		pixel |= uint32(b[i]) << uint(8*i)
+	}
+	return
+}
+
+// Stores a pixel of bpp bytes in the byte order of the machine
+// (the same layout as setPixel).
+func putPixelNative(b []byte, bpp int, pixel uint32) {
+	if BYTEORDER() == LIL_ENDIAN {
+		putPixelLE(b, bpp, pixel)
+		return
+	}
+	for i := 0; i < bpp; i++ {
+		b[bpp-1-i] = uint8(pixel >> uint(8*i))
+	}
+}
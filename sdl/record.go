@@ -0,0 +1,133 @@
+package sdl
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"sync"
+)
+
+// An event as stored by RecordEvents
+type recordedEvent struct {
+	Time  uint32 // Milliseconds since the start of the recording
+	Event interface{}
+}
+
+var recorder struct {
+	sync.Mutex
+	encoder *gob.Encoder
+	start   uint32
+}
+
+func init() {
+	gob.Register(QuitEvent{})
+	gob.Register(KeyboardEvent{})
+	gob.Register(MouseButtonEvent{})
+	gob.Register(MouseMotionEvent{})
+	gob.Register(ActiveEvent{})
+	gob.Register(ResizeEvent{}) // In recordings made before VideoResizeEvent
+	gob.Register(VideoResizeEvent{})
+	gob.Register(JoyAxisEvent{})
+	gob.Register(JoyButtonEvent{})
+	gob.Register(JoyHatEvent{})
+	gob.Register(JoyBallEvent{})
+}
+
+// Starts writing every event delivered by Events to w, together with the time
+// it was received (relative to the start of the recording). The stream is
+// gob-encoded and can be fed back with ReplayEvents, for example to replay
+// a bug report in a test.
+//
+// Calling RecordEvents again replaces the writer, calling it with nil stops
+// the recording. The recording also stops if writing to w fails,
+// the error is delivered by Errors.
+func RecordEvents(w io.Writer) {
+	recorder.Lock()
+	if w != nil {
+		recorder.encoder = gob.NewEncoder(w)
+		recorder.start = GetTicks()
+	} else {
+		recorder.encoder = nil
+	}
+	recorder.Unlock()
+}
+
+// Called by pollEvents for each event delivered by Events
+func recordEvent(e interface{}) {
+	recorder.Lock()
+	if recorder.encoder != nil {
+		r := recordedEvent{GetTicks() - recorder.start, e}
+		if err := recorder.encoder.Encode(&r); err != nil {
+			recorder.encoder = nil
+			reportError(err)
+		}
+	}
+	recorder.Unlock()
+}
+
+// Reads events recorded by RecordEvents from r and pushes them onto the event
+// queue (see PushEvent), keeping the time between them as recorded.
+// Returns when the whole stream has been replayed, or on the first error.
+func ReplayEvents(r io.Reader) error {
+	decoder := gob.NewDecoder(r)
+	start := GetTicks()
+
+	for {
+		var rec recordedEvent
+		if err := decoder.Decode(&rec); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if elapsed := GetTicks() - start; elapsed < rec.Time {
+			Delay(rec.Time - elapsed)
+		}
+
+		if event, ok := rawEvent(rec.Event); ok {
+			for PushEvent(&event) < 0 {
+				if WasInit(INIT_VIDEO) == 0 {
+					return errors.New(GetError())
+				}
+				// The queue is full, give the application time to drain it
+				Delay(poll_interval_ms)
+			}
+		}
+	}
+}
+
+// Converts a value delivered by Events back to a raw event.
+// This is the inverse of typedEvent.
+func rawEvent(e interface{}) (event Event, ok bool) {
+	p := cast(&event)
+
+	switch e := e.(type) {
+	case QuitEvent:
+		*(*QuitEvent)(p) = e
+	case KeyboardEvent:
+		*(*KeyboardEvent)(p) = e
+	case MouseButtonEvent:
+		*(*MouseButtonEvent)(p) = e
+	case MouseMotionEvent:
+		*(*MouseMotionEvent)(p) = e
+	case ActiveEvent:
+		*(*ActiveEvent)(p) = e
+	case ResizeEvent: // In recordings made before VideoResizeEvent
+		*(*ResizeEvent)(p) = e
+	case VideoResizeEvent:
+		*(*ResizeEvent)(p) = e.ResizeEvent
+	case JoyAxisEvent:
+		*(*JoyAxisEvent)(p) = e
+	case JoyButtonEvent:
+		*(*JoyButtonEvent)(p) = e
+	case JoyHatEvent:
+		*(*JoyHatEvent)(p) = e
+	case JoyBallEvent:
+		*(*JoyBallEvent)(p) = e
+	default:
+		return event, false
+	}
+
+	return event, true
+}
@@ -17,12 +17,34 @@ package sdl
 // #include <SDL_image.h>
 // static void SetError(const char* description){SDL_SetError("%s",description);}
 // static int __SDL_SaveBMP(SDL_Surface *surface, const char *file) { return SDL_SaveBMP(surface, file); }
+// static int __SDL_MUSTLOCK(SDL_Surface *surface) { return SDL_MUSTLOCK(surface); }
+// static Uint16 __SDL_SwapLE16(Uint16 x) { return SDL_SwapLE16(x); }
+// static Uint32 __SDL_SwapLE32(Uint32 x) { return SDL_SwapLE32(x); }
+// static Uint16 __SDL_SwapBE16(Uint16 x) { return SDL_SwapBE16(x); }
+// static Uint32 __SDL_SwapBE32(Uint32 x) { return SDL_SwapBE32(x); }
+//
+// // IMG_Init first appeared in SDL_image 1.2.10
+// static int __IMG_Init(int flags) {
+// #if SDL_VERSIONNUM(SDL_IMAGE_MAJOR_VERSION, SDL_IMAGE_MINOR_VERSION, SDL_IMAGE_PATCHLEVEL) >= SDL_VERSIONNUM(1, 2, 10)
+// 	return IMG_Init(flags);
+// #else
+// 	return -1;
+// #endif
+// }
+// static void __IMG_Quit() {
+// #if SDL_VERSIONNUM(SDL_IMAGE_MAJOR_VERSION, SDL_IMAGE_MINOR_VERSION, SDL_IMAGE_PATCHLEVEL) >= SDL_VERSIONNUM(1, 2, 10)
+// 	IMG_Quit();
+// #endif
+// }
 import "C"
 
 import (
+	"errors"
 	"os"
 	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 	"reflect"
@@ -40,6 +62,59 @@ type cast unsafe.Pointer
 // There is no dependency between 'Surface.Lock' and the global mutex.
 var GlobalMutex sync.Mutex
 
+// Non-zero in single-threaded mode, see SetSingleThreaded
+var singleThreadedMode int32
+
+// Locks GlobalMutex, unless single-threaded mode is on.
+func lockGlobal() {
+	if atomic.LoadInt32(&singleThreadedMode) == 0 {
+		GlobalMutex.Lock()
+	}
+}
+
+// Unlocks GlobalMutex, unless single-threaded mode is on.
+func unlockGlobal() {
+	if atomic.LoadInt32(&singleThreadedMode) == 0 {
+		GlobalMutex.Unlock()
+	}
+}
+
+// Turns single-threaded mode on or off. In single-threaded mode the functions
+// of this package don't lock GlobalMutex. This removes the locking overhead
+// from every call, which adds up in tight loops such as thousands of blits
+// per frame (see BenchmarkLockGlobal and BenchmarkLockGlobalSingleThreaded).
+//
+// This is only safe if all SDL calls are made from one goroutine. The mode
+// must be switched while no other goroutine uses this package, typically at
+// the start of main.
+//
+// Events are normally polled by a background goroutine, which is stopped
+// while single-threaded mode is on, and started again when it is turned off.
+// Meanwhile Events delivers nothing: poll the events with PollEvent from the
+// goroutine making the SDL calls (EventDispatcher.Run does that itself).
+func SetSingleThreaded(enabled bool) {
+	if enabled == singleThreaded() {
+		return
+	}
+
+	if enabled {
+		stopPolling()
+
+		// Wait until the mutex is free, so that it isn't left locked
+		GlobalMutex.Lock()
+		atomic.StoreInt32(&singleThreadedMode, 1)
+		GlobalMutex.Unlock()
+	} else {
+		atomic.StoreInt32(&singleThreadedMode, 0)
+		go pollEvents()
+	}
+}
+
+// Reports whether single-threaded mode is on, see SetSingleThreaded.
+func singleThreaded() bool {
+	return atomic.LoadInt32(&singleThreadedMode) != 0
+}
+
 type Surface struct {
 	cSurface *C.SDL_Surface
 	mutex    sync.RWMutex
@@ -52,7 +127,21 @@ type Surface struct {
 	Pixels unsafe.Pointer
 	Offset int32
 
-	gcPixels interface{} // Prevents garbage collection of pixels passed to func CreateRGBSurfaceFrom
+	gcPixels  interface{} // Prevents garbage collection of pixels passed to func CreateRGBSurfaceFrom
+	blendMode BlendMode   // See SetBlendMode
+	lockDepth int         // Number of Lock calls not matched by Unlock yet
+
+	// See SurfaceMemoryStats
+	accounted         bool  // Whether the surface is counted
+	accountedExternal bool  // Whether it is counted as created by CreateRGBSurfaceFrom
+	accountedBytes    int64 // The size of the pixel data counted
+
+	// See DisplayFormatCached
+	modified           bool     // Set by the functions that may change the pixels
+	displayFormatCache *Surface // The converted surface
+	displayFormatFor   *Surface // The video surface it was converted for
+
+	glPixels *Surface // The RGBA copy returned by GLPixels
 }
 
 func wrap(cSurface *C.SDL_Surface) *Surface {
@@ -110,9 +199,89 @@ func GoSdlVersion() string {
 	return "⚛SDL bindings 1.0"
 }
 
+// Returns the version of the SDL library the program is running with.
+func LinkedVersion() (major, minor, patch int) {
+	v := C.SDL_Linked_Version()
+	return int(v.major), int(v.minor), int(v.patch)
+}
+
+// Returns the version of the SDL headers the bindings were compiled against.
+// It can differ from LinkedVersion if the shared library was upgraded since.
+func CompiledVersion() (major, minor, patch int) {
+	return C.SDL_MAJOR_VERSION, C.SDL_MINOR_VERSION, C.SDL_PATCHLEVEL
+}
+
+// Returns the version of the SDL_image library the program is running with.
+func ImgLinkedVersion() (major, minor, patch int) {
+	v := C.IMG_Linked_Version()
+	return int(v.major), int(v.minor), int(v.patch)
+}
+
+// Returns the names of the image formats Load can decode, such as "PNG" or "JPG".
+//
+// The formats built into SDL_image are always reported. JPG, PNG, TIF and WEBP
+// depend on external libraries, which are probed by loading them with IMG_Init.
+// If no library was loaded before (see ImgInit), they are unloaded again with
+// IMG_Quit; otherwise they stay loaded, since SDL_image can only unload all
+// of them at once. SDL_image older than 1.2.10 has no way to probe them,
+// in that case JPG, PNG and TIF are assumed to be available.
+func SupportedImageFormats() []string {
+	formats := []string{"BMP", "GIF", "LBM", "PCX", "PNM", "TGA", "XCF", "XPM", "XV"}
+
+	lockGlobal()
+	before := int(C.__IMG_Init(0)) // The flags of the libraries loaded so far
+	loaded := int(C.__IMG_Init(C.int(INIT_JPG | INIT_PNG | INIT_TIF | INIT_WEBP)))
+	if before == 0 && loaded > 0 {
+		C.__IMG_Quit()
+	}
+	unlockGlobal()
+
+	if loaded < 0 {
+		loaded = INIT_JPG | INIT_PNG | INIT_TIF
+	}
+
+	if loaded&INIT_JPG != 0 {
+		formats = append(formats, "JPG")
+	}
+	if loaded&INIT_PNG != 0 {
+		formats = append(formats, "PNG")
+	}
+	if loaded&INIT_TIF != 0 {
+		formats = append(formats, "TIF")
+	}
+	if loaded&INIT_WEBP != 0 {
+		formats = append(formats, "WEBP")
+	}
+
+	return formats
+}
+
+// Loads the libraries SDL_image needs for the given formats, a combination
+// of INIT_JPG, INIT_PNG, INIT_TIF and INIT_WEBP. Without this they are loaded
+// when the first image of the format is decoded, which can cause a hitch.
+// Returns the flags of all formats that are loaded now, check them against
+// the requested flags to find out which failed.
+//
+// SDL_image older than 1.2.10 has no IMG_Init, in that case
+// this does nothing and returns -1.
+func ImgInit(flags int) int {
+	lockGlobal()
+	loaded := int(C.__IMG_Init(C.int(flags)))
+	unlockGlobal()
+	return loaded
+}
+
+// Unloads the libraries loaded by ImgInit. Does nothing with
+// SDL_image older than 1.2.10.
+func ImgQuit() {
+	lockGlobal()
+	C.__IMG_Quit()
+	unlockGlobal()
+}
+
 // Initializes SDL.
 func Init(flags uint32) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	status := int(C.SDL_Init(C.Uint32(flags)))
 	if (status != 0) && (runtime.GOOS == "darwin") && (flags&INIT_VIDEO != 0) {
 		if os.Getenv("SDL_VIDEODRIVER") == "" {
@@ -124,27 +293,66 @@ func Init(flags uint32) int {
 		}
 	}
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if status != 0 {
+		logError("Init")
+	}
+
 	return status
 }
 
+// Initializes SDL with the dummy video and audio drivers, which need no display
+// and no sound card. Video surfaces are then plain memory buffers, so rendering
+// works as usual but nothing is shown. This is meant for tests and CI machines.
+//
+// On failure the previous values of SDL_VIDEODRIVER and SDL_AUDIODRIVER are restored.
+func InitHeadless(flags uint32) error {
+	vars := []string{"SDL_VIDEODRIVER", "SDL_AUDIODRIVER"}
+	saved := make([]string, len(vars))
+	wasSet := make([]bool, len(vars))
+
+	for i, name := range vars {
+		saved[i], wasSet[i] = os.LookupEnv(name)
+		os.Setenv(name, "dummy")
+	}
+
+	if Init(flags) == 0 {
+		return nil
+	}
+
+	err := errors.New(GetError())
+
+	for i, name := range vars {
+		if wasSet[i] {
+			os.Setenv(name, saved[i])
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+
+	return err
+}
+
 // Shuts down SDL
 func Quit() {
-	GlobalMutex.Lock()
+	lockGlobal()
 
 	if currentVideoSurface != nil {
 		currentVideoSurface.destroy()
 		currentVideoSurface = nil
 	}
+	videoInfo = nil
+	windowW, windowH, resizedFromW, resizedFromH = 0, 0, 0, 0
 
 	C.SDL_Quit()
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
 }
 
 // Initializes subsystems.
 func InitSubSystem(flags uint32) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	status := int(C.SDL_InitSubSystem(C.Uint32(flags)))
 	if (status != 0) && (runtime.GOOS == "darwin") && (flags&INIT_VIDEO != 0) {
 		if os.Getenv("SDL_VIDEODRIVER") == "" {
@@ -155,53 +363,92 @@ func InitSubSystem(flags uint32) int {
 			}
 		}
 	}
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if status != 0 {
+		logError("InitSubSystem")
+	}
+
 	return status
 }
 
 // Shuts down a subsystem.
 func QuitSubSystem(flags uint32) {
-	GlobalMutex.Lock()
+	lockGlobal()
 	C.SDL_QuitSubSystem(C.Uint32(flags))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 }
 
 // Checks which subsystems are initialized.
 func WasInit(flags uint32) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	status := int(C.SDL_WasInit(C.Uint32(flags)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return status
 }
 
+// Reports whether the video subsystem is initialized, by Init, InitSubSystem
+// or SetVideoMode. If it isn't, sets the SDL error to a message saying so,
+// prefixed with the name of the function.
+//
+// Guards the functions that SDL 1.2 doesn't check itself and that crash
+// (or silently misbehave) when called too early, the most common mistake
+// in new programs. Must be called with GlobalMutex locked.
+func videoInitialized(function string) bool {
+	if C.SDL_WasInit(C.SDL_INIT_VIDEO) != 0 {
+		return true
+	}
+
+	cdescription := C.CString(function + ": SDL video not initialized")
+	C.SetError(cdescription)
+	C.free(unsafe.Pointer(cdescription))
+
+	return false
+}
+
 // ==============
 // Error Handling
 // ==============
 
 // Gets SDL error string
 func GetError() string {
-	GlobalMutex.Lock()
+	lockGlobal()
 	s := C.GoString(C.SDL_GetError())
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return s
 }
 
 // Set a string describing an error to be submitted to the SDL Error system.
 func SetError(description string) {
-	GlobalMutex.Lock()
+	lockGlobal()
 
 	cdescription := C.CString(description)
 	C.SetError(cdescription)
 	C.free(unsafe.Pointer(cdescription))
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
 }
 
 // Clear the current SDL error
 func ClearError() {
-	GlobalMutex.Lock()
+	lockGlobal()
+	C.SDL_ClearError()
+	unlockGlobal()
+}
+
+// Returns the current SDL error as an error and clears it, or returns nil
+// if there is no error. Unlike GetError, this tells "no error" apart from
+// an empty message, so it can be used after any call to check whether it failed.
+func CheckError() error {
+	lockGlobal()
+	s := C.GoString(C.SDL_GetError())
 	C.SDL_ClearError()
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
 }
 
 // ======
@@ -210,31 +457,179 @@ func ClearError() {
 
 var currentVideoSurface *Surface = nil
 
+// The bpp and flags passed to the last SetVideoMode call
+var videoModeBpp int
+var videoModeFlags uint32
+
+// Called when the OpenGL context may have been recreated, with the new size
+// of the screen. See SetGLContextListener.
+type GLContextListener func(width, height int)
+
+var glContextListener GLContextListener
+
+// Sets a function to be called whenever the OpenGL context may have been
+// recreated, losing all textures, display lists and other GL state.
+// A nil listener removes the previous one.
+//
+// SDL 1.2 handles a VIDEORESIZE event (and the fallback of ToggleFullScreen)
+// by setting the video mode again. On Windows and Mac OS X this always
+// creates a new OpenGL context, on X11 the context usually survives.
+// The listener is called after SetVideoMode or ToggleFullScreen has set
+// a new OPENGL mode replacing a previous one, from the goroutine that
+// called them, so it can recreate the GL resources right away.
+func SetGLContextListener(listener GLContextListener) {
+	lockGlobal()
+	glContextListener = listener
+	unlockGlobal()
+}
+
+// Returns the listener to call after a new video mode was set with the given
+// flags, or nil. Must be called with GlobalMutex locked, before videoModeFlags
+// is updated.
+func glContextLost(screen *C.SDL_Surface, flags uint32) GLContextListener {
+	if screen != nil && flags&OPENGL != 0 && videoModeFlags&OPENGL != 0 {
+		return glContextListener
+	}
+	return nil
+}
+
 // Sets up a video mode with the specified width, height, bits-per-pixel and
 // returns a corresponding surface.  You don't need to call the Free method
 // of the returned surface, as it will be done automatically by sdl.Quit.
 func SetVideoMode(w int, h int, bpp int, flags uint32) *Surface {
-	GlobalMutex.Lock()
+	lockGlobal()
 	var screen = C.SDL_SetVideoMode(C.int(w), C.int(h), C.int(bpp), C.Uint32(flags))
+	listener := glContextLost(screen, flags)
 	currentVideoSurface = wrap(screen)
-	GlobalMutex.Unlock()
-	return currentVideoSurface
+	videoInfo = nil
+	videoModeBpp = bpp
+	videoModeFlags = flags
+	surface := currentVideoSurface
+	if surface != nil {
+		windowW, windowH = surface.W, surface.H
+	}
+	unlockGlobal()
+
+	if listener != nil {
+		listener(int(surface.W), int(surface.H))
+	}
+
+	if surface == nil {
+		logError("SetVideoMode")
+	} else {
+		notifyResize()
+	}
+
+	return surface
+}
+
+// Switches between windowed and fullscreen mode and returns the screen surface,
+// or nil if there is no video mode.
+//
+// Unlike WM_ToggleFullScreen, which only works on some platforms (such as X11),
+// this falls back to setting the video mode again with the FULLSCREEN flag toggled,
+// keeping the size, bpp and other flags of the last SetVideoMode call.
+// In that case the returned surface replaces the previous screen surface
+// and its contents have to be redrawn. If that video mode can't be set,
+// the error is passed to the logger (see SetLogger) and the previous mode
+// is set again, so the returned surface has the FULLSCREEN flag unchanged;
+// nil is returned only if that fails too.
+func ToggleFullScreen() *Surface {
+	var listener GLContextListener
+	var failure string
+
+	lockGlobal()
+
+	screen := currentVideoSurface
+	if screen != nil {
+		if C.SDL_WM_ToggleFullScreen(screen.cSurface) != 0 {
+			screen.reload()
+			videoModeFlags ^= FULLSCREEN
+			videoInfo = nil
+		} else {
+			w, h, bpp := C.int(screen.W), C.int(screen.H), C.int(videoModeBpp)
+			flags := videoModeFlags ^ FULLSCREEN
+			cScreen := C.SDL_SetVideoMode(w, h, bpp, C.Uint32(flags))
+			if cScreen == nil {
+				failure = C.GoString(C.SDL_GetError())
+				flags = videoModeFlags
+				cScreen = C.SDL_SetVideoMode(w, h, bpp, C.Uint32(flags))
+			}
+			listener = glContextLost(cScreen, flags)
+			currentVideoSurface = wrap(cScreen)
+			videoInfo = nil
+			if cScreen != nil {
+				videoModeFlags = flags
+			}
+		}
+	}
+
+	screen = currentVideoSurface
+	if screen != nil {
+		windowW, windowH = screen.W, screen.H
+	}
+	unlockGlobal()
+
+	if listener != nil {
+		listener(int(screen.W), int(screen.H))
+	}
+
+	if failure != "" {
+		if screen != nil {
+			// Report why the switch failed, not the state after restoring
+			SetError(failure)
+		}
+		logError("ToggleFullScreen")
+	}
+	if screen != nil {
+		notifyResize()
+	}
+
+	return screen
+}
+
+// Makes the window open at the given position on the screen.
+//
+// SDL 1.2 reads the position from the environment when the window is created,
+// so this must be called before SetVideoMode, it takes effect at the next
+// call to SetVideoMode. Overrides a previous CenterWindow.
+func SetWindowPosition(x, y int) error {
+	if err := os.Unsetenv("SDL_VIDEO_CENTERED"); err != nil {
+		return err
+	}
+	return os.Setenv("SDL_VIDEO_WINDOW_POS", strconv.Itoa(x)+","+strconv.Itoa(y))
+}
+
+// Makes the window open centered on the screen.
+//
+// SDL 1.2 reads this setting from the environment when the window is created,
+// so this must be called before SetVideoMode, it takes effect at the next
+// call to SetVideoMode. Overrides a previous SetWindowPosition.
+func CenterWindow() error {
+	if err := os.Unsetenv("SDL_VIDEO_WINDOW_POS"); err != nil {
+		return err
+	}
+	return os.Setenv("SDL_VIDEO_CENTERED", "1")
 }
 
 // Returns a pointer to the current display surface.
 func GetVideoSurface() *Surface {
-	GlobalMutex.Lock()
+	lockGlobal()
 	surface := currentVideoSurface
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return surface
 }
 
 // Checks to see if a particular video mode is supported.  Returns 0 if not
 // supported, or the bits-per-pixel of the closest available mode.
 func VideoModeOK(width int, height int, bpp int, flags uint32) int {
-	GlobalMutex.Lock()
+	lockGlobal()
+	if !videoInitialized("VideoModeOK") {
+		unlockGlobal()
+		return 0
+	}
 	status := int(C.SDL_VideoModeOK(C.int(width), C.int(height), C.int(bpp), C.Uint32(flags)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return status
 }
 
@@ -243,17 +638,37 @@ func VideoModeOK(width int, height int, bpp int, flags uint32) int {
 // NOTE: The result of this function uses a different encoding than the underlying C function.
 // It returns an empty array if no modes are available,
 // and nil if any dimension is okay for the given format.
+//
+// Before the video subsystem is initialized, no modes are available;
+// use ListModesChecked to tell that case apart.
 func ListModes(format *PixelFormat, flags uint32) []Rect {
+	modes, err := ListModesChecked(format, flags)
+	if err != nil {
+		return make([]Rect, 0)
+	}
+	return modes
+}
+
+// Like ListModes, but returns an error if the video subsystem
+// isn't initialized.
+func ListModesChecked(format *PixelFormat, flags uint32) ([]Rect, error) {
+	lockGlobal()
+	if !videoInitialized("ListModes") {
+		err := errors.New(C.GoString(C.SDL_GetError()))
+		unlockGlobal()
+		return nil, err
+	}
 	modes := C.SDL_ListModes((*C.SDL_PixelFormat)(cast(format)), C.Uint32(flags))
+	unlockGlobal()
 
 	// No modes available
 	if modes == nil {
-		return make([]Rect, 0)
+		return make([]Rect, 0), nil
 	}
 
 	// (modes == -1) --> Any dimension is ok
 	if uintptr(unsafe.Pointer(modes))+1 == uintptr(0) {
-		return nil
+		return nil, nil
 	}
 
 	count := 0
@@ -273,7 +688,35 @@ func ListModes(format *PixelFormat, flags uint32) []Rect {
 		ret[i].H = uint16(r.h)
 	}
 
-	return ret
+	return ret, nil
+}
+
+// Returns the largest fullscreen mode available for the given format
+// (nil for the format of the best video mode), usually the native resolution
+// of the screen, to pass to SetVideoMode with FULLSCREEN.
+// ok is false if no fullscreen mode is available.
+//
+// If any size is fine for the format (ListModes returns nil), the size of
+// the desktop is returned instead, as reported by GetVideoInfo; it is zero
+// if SDL is older than 1.2.10, which doesn't report it.
+func BestFullscreenMode(format *PixelFormat) (size Size, ok bool) {
+	modes := ListModes(format, FULLSCREEN)
+
+	if modes == nil {
+		if info := GetVideoInfo(); info != nil {
+			size = Size{uint16(info.Current_w), uint16(info.Current_h)}
+		}
+		return size, true
+	}
+
+	for _, m := range modes {
+		if int(m.W)*int(m.H) > int(size.W)*int(size.H) {
+			size = Size{m.W, m.H}
+			ok = true
+		}
+	}
+
+	return
 }
 
 type VideoInfo struct {
@@ -292,14 +735,50 @@ type VideoInfo struct {
 	Current_h    int32        "Value: The current video mode height"
 }
 
+// The result of GetVideoInfo, reset when the video mode changes.
+// Protected by GlobalMutex.
+var videoInfo *VideoInfo
+
+// Gets information about the video hardware and the current video mode,
+// or nil if the video subsystem isn't initialized.
+//
+// The information is queried once and kept until the video mode is changed
+// by SetVideoMode, ToggleFullScreen or WM_ToggleFullScreen, so reading
+// Current_w and Current_h every frame is cheap. The result is shared by
+// all callers and must not be modified. If the mode may have changed
+// in another way, use RefreshVideoInfo.
 func GetVideoInfo() *VideoInfo {
-	GlobalMutex.Lock()
+	lockGlobal()
+	info := videoInfo
+	unlockGlobal()
+
+	if info != nil {
+		return info
+	}
+	return RefreshVideoInfo()
+}
+
+// Queries the information returned by GetVideoInfo again and returns it,
+// or nil if the video subsystem isn't initialized.
+func RefreshVideoInfo() *VideoInfo {
+	lockGlobal()
+
+	if !videoInitialized("GetVideoInfo") {
+		videoInfo = nil
+		unlockGlobal()
+		return nil
+	}
+
 	vinfo := (*internalVideoInfo)(cast(C.SDL_GetVideoInfo()))
-	GlobalMutex.Unlock()
+	if vinfo == nil {
+		videoInfo = nil
+		unlockGlobal()
+		return nil
+	}
 
 	flags := vinfo.Flags
 
-	return &VideoInfo{
+	info := &VideoInfo{
 		HW_available: flags&(1<<0) != 0,
 		WM_available: flags&(1<<1) != 0,
 		Blit_hw:      flags&(1<<9) != 0,
@@ -314,43 +793,68 @@ func GetVideoInfo() *VideoInfo {
 		Current_w:    vinfo.Current_w,
 		Current_h:    vinfo.Current_h,
 	}
+	videoInfo = info
+
+	unlockGlobal()
+
+	return info
 }
 
 // Makes sure the given area is updated on the given screen.  If x, y, w, and
-// h are all 0, the whole screen will be updated.
+// h are all 0, the whole screen will be updated (Update is a clearer way to say so).
+// Does nothing but set the SDL error if the video subsystem isn't initialized.
 func (screen *Surface) UpdateRect(x int32, y int32, w uint32, h uint32) {
-	GlobalMutex.Lock()
+	lockGlobal()
+	if !videoInitialized("UpdateRect") {
+		unlockGlobal()
+		return
+	}
 	screen.mutex.Lock()
 
 	C.SDL_UpdateRect(screen.cSurface, C.Sint32(x), C.Sint32(y), C.Uint32(w), C.Uint32(h))
 
 	screen.mutex.Unlock()
-	GlobalMutex.Unlock()
+	unlockGlobal()
+}
+
+// Makes sure the whole screen is updated.
+func (screen *Surface) Update() {
+	screen.UpdateRect(0, 0, 0, 0)
 }
 
+// Makes sure the given areas are updated on the given screen. Does nothing
+// but set the SDL error if the video subsystem isn't initialized.
 func (screen *Surface) UpdateRects(rects []Rect) {
 	if len(rects) > 0 {
-		GlobalMutex.Lock()
+		lockGlobal()
+		if !videoInitialized("UpdateRects") {
+			unlockGlobal()
+			return
+		}
 		screen.mutex.Lock()
 
 		C.SDL_UpdateRects(screen.cSurface, C.int(len(rects)), (*C.SDL_Rect)(cast(&rects[0])))
 
 		screen.mutex.Unlock()
-		GlobalMutex.Unlock()
+		unlockGlobal()
 	}
 }
 
 // Gets the window title and icon name.
+// Both are empty if no caption has been set yet.
 func WM_GetCaption() (title, icon string) {
-	GlobalMutex.Lock()
+	lockGlobal()
 
-	// SDL seems to free these strings.  TODO: Check to see if that's the case
+	// The strings are owned by SDL and stay valid only until the next call
+	// to SDL_WM_SetCaption (which frees them) or SDL_Quit. They must not be freed here.
+	// They are copied while holding the global mutex, which WM_SetCaption and Quit
+	// also hold, so they can't be freed while being copied.
 	var ctitle, cicon *C.char
 	C.SDL_WM_GetCaption(&ctitle, &cicon)
 	title = C.GoString(ctitle)
 	icon = C.GoString(cicon)
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
 
 	return
 }
@@ -360,9 +864,9 @@ func WM_SetCaption(title, icon string) {
 	ctitle := C.CString(title)
 	cicon := C.CString(icon)
 
-	GlobalMutex.Lock()
+	lockGlobal()
 	C.SDL_WM_SetCaption(ctitle, cicon)
-	GlobalMutex.Unlock()
+	unlockGlobal()
 
 	C.free(unsafe.Pointer(ctitle))
 	C.free(unsafe.Pointer(cicon))
@@ -370,104 +874,489 @@ func WM_SetCaption(title, icon string) {
 
 // Sets the icon for the display window.
 func WM_SetIcon(icon *Surface, mask *uint8) {
-	GlobalMutex.Lock()
+	lockGlobal()
 	C.SDL_WM_SetIcon(icon.cSurface, (*C.Uint8)(mask))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 }
 
 // Minimizes the window
 func WM_IconifyWindow() int {
-	GlobalMutex.Lock()
+	lockGlobal()
+	if !videoInitialized("WM_IconifyWindow") {
+		unlockGlobal()
+		return 0
+	}
 	status := int(C.SDL_WM_IconifyWindow())
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return status
 }
 
 // Toggles fullscreen mode
 func WM_ToggleFullScreen(surface *Surface) int {
-	GlobalMutex.Lock()
+	lockGlobal()
+	if !videoInitialized("WM_ToggleFullScreen") {
+		unlockGlobal()
+		return 0
+	}
 	status := int(C.SDL_WM_ToggleFullScreen(surface.cSurface))
-	GlobalMutex.Unlock()
+	videoInfo = nil
+	unlockGlobal()
+	return status
+}
+
+// Grabs mouse and keyboard input. The mode is one of GRAB_QUERY,
+// GRAB_OFF or GRAB_ON. Returns the current (or new) mode, or GRAB_OFF
+// with the SDL error set if the video subsystem isn't initialized.
+func WM_GrabInput(mode int) int {
+	lockGlobal()
+	if !videoInitialized("WM_GrabInput") {
+		unlockGlobal()
+		return GRAB_OFF
+	}
+	status := int(C.SDL_WM_GrabInput(C.SDL_GrabMode(mode)))
+	unlockGlobal()
 	return status
 }
 
+// Whether GL_DOUBLEBUFFER was requested, SDL requests it by default
+var glDoubleBuffer = true
+
+// Reports whether the current video mode was set with the OPENGL flag.
+func GL_Supported() bool {
+	lockGlobal()
+	supported := currentVideoSurface != nil && currentVideoSurface.Flags&OPENGL != 0
+	unlockGlobal()
+	return supported
+}
+
 // Swaps OpenGL framebuffers/Update Display.
+//
+// Swapping is only defined for a double-buffered OpenGL context, so this
+// does nothing if the video mode wasn't set with OPENGL, or if GL_DOUBLEBUFFER
+// was set to 0 with GL_SetAttribute. Use GL_SwapBuffersChecked to find out.
 func GL_SwapBuffers() {
-	GlobalMutex.Lock()
-	C.SDL_GL_SwapBuffers()
-	GlobalMutex.Unlock()
+	GL_SwapBuffersChecked()
+}
+
+// Like GL_SwapBuffers, but returns an error if nothing was swapped.
+func GL_SwapBuffersChecked() error {
+	if !GL_Supported() {
+		return errors.New("GL_SwapBuffers: the video mode was not set with OPENGL")
+	}
+
+	lockGlobal()
+	doubleBuffer := glDoubleBuffer
+	if doubleBuffer {
+		C.SDL_GL_SwapBuffers()
+	}
+	unlockGlobal()
+
+	if !doubleBuffer {
+		return errors.New("GL_SwapBuffers: the OpenGL context is not double-buffered (see GL_DOUBLEBUFFER)")
+	}
+	return nil
 }
 
 func GL_SetAttribute(attr int, value int) int {
-	GlobalMutex.Lock()
+	lockGlobal()
+	if !videoInitialized("GL_SetAttribute") {
+		unlockGlobal()
+		return -1
+	}
 	status := int(C.SDL_GL_SetAttribute(C.SDL_GLattr(attr), C.int(value)))
-	GlobalMutex.Unlock()
+	if status == 0 && attr == GL_DOUBLEBUFFER {
+		glDoubleBuffer = value != 0
+	}
+	unlockGlobal()
 	return status
 }
 
+// Gets the actual value of an OpenGL attribute after the video mode was set,
+// which can differ from the value requested with GL_SetAttribute.
+// Returns 0 on success, -1 on error.
+func GL_GetAttribute(attr int, value *int) int {
+	var cValue C.int
+	lockGlobal()
+	if !videoInitialized("GL_GetAttribute") {
+		unlockGlobal()
+		return -1
+	}
+	status := int(C.SDL_GL_GetAttribute(C.SDL_GLattr(attr), &cValue))
+	unlockGlobal()
+	*value = int(cValue)
+	return status
+}
+
+// The actual configuration of the OpenGL framebuffer, see GL_ActualConfig.
+// The fields correspond to the GL_* attributes.
+type GLConfig struct {
+	RedSize, GreenSize, BlueSize, AlphaSize int
+	BufferSize                              int
+	DoubleBuffer                            int
+	DepthSize, StencilSize                  int
+	AccumRedSize, AccumGreenSize            int
+	AccumBlueSize, AccumAlphaSize           int
+	Stereo                                  int
+	MultisampleBuffers, MultisampleSamples  int
+	AcceleratedVisual                       int
+	SwapControl                             int
+}
+
+// Returns the actual configuration of the OpenGL framebuffer, to check
+// whether the attributes requested with GL_SetAttribute were honored
+// (for example, a 16-bit depth buffer instead of a 24-bit one).
+// Must be called after SetVideoMode with OPENGL. Attributes that
+// can't be queried are left 0.
+func GL_ActualConfig() GLConfig {
+	var c GLConfig
+
+	attrs := []struct {
+		attr  int
+		value *int
+	}{
+		{GL_RED_SIZE, &c.RedSize},
+		{GL_GREEN_SIZE, &c.GreenSize},
+		{GL_BLUE_SIZE, &c.BlueSize},
+		{GL_ALPHA_SIZE, &c.AlphaSize},
+		{GL_BUFFER_SIZE, &c.BufferSize},
+		{GL_DOUBLEBUFFER, &c.DoubleBuffer},
+		{GL_DEPTH_SIZE, &c.DepthSize},
+		{GL_STENCIL_SIZE, &c.StencilSize},
+		{GL_ACCUM_RED_SIZE, &c.AccumRedSize},
+		{GL_ACCUM_GREEN_SIZE, &c.AccumGreenSize},
+		{GL_ACCUM_BLUE_SIZE, &c.AccumBlueSize},
+		{GL_ACCUM_ALPHA_SIZE, &c.AccumAlphaSize},
+		{GL_STEREO, &c.Stereo},
+		{GL_MULTISAMPLEBUFFERS, &c.MultisampleBuffers},
+		{GL_MULTISAMPLESAMPLES, &c.MultisampleSamples},
+		{GL_ACCELERATED_VISUAL, &c.AcceleratedVisual},
+		{GL_SWAP_CONTROL, &c.SwapControl},
+	}
+
+	for _, a := range attrs {
+		var value int
+		if GL_GetAttribute(a.attr, &value) == 0 {
+			*a.value = value
+		}
+	}
+
+	return c
+}
+
 // Swaps screen buffers.
 func (screen *Surface) Flip() int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	screen.mutex.Lock()
 
 	status := int(C.SDL_Flip(screen.cSurface))
 
 	screen.mutex.Unlock()
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if status != 0 {
+		logError("Flip")
+	}
 
 	return status
 }
 
+// Presents the contents of the screen surface, choosing the right call
+// for how the video mode was set up: GL_SwapBuffers for OPENGL,
+// Flip for DOUBLEBUF, and Update otherwise.
+// Returns 0 on success, -1 on error.
+func (screen *Surface) Present() int {
+	switch {
+	case screen.Flags&OPENGL != 0:
+		if GL_SwapBuffersChecked() != nil {
+			return -1
+		}
+		return 0
+	case screen.Flags&DOUBLEBUF != 0:
+		return screen.Flip()
+	}
+
+	screen.Update()
+	return 0
+}
+
+// Presents only the given areas of the screen surface.
+//
+// A double-buffered (or OpenGL) screen can't be updated partially,
+// so in that case the rects are ignored and the whole screen is presented.
+func (screen *Surface) PresentRects(rects []Rect) int {
+	if screen.Flags&(OPENGL|DOUBLEBUF) != 0 {
+		return screen.Present()
+	}
+
+	screen.UpdateRects(rects)
+	return 0
+}
+
 // Frees (deletes) a Surface
 func (screen *Surface) Free() {
-	GlobalMutex.Lock()
+	lockGlobal()
 	screen.mutex.Lock()
 
 	C.SDL_FreeSurface(screen.cSurface)
 
+	screen.unaccount()
 	screen.destroy()
 	if screen == currentVideoSurface {
 		currentVideoSurface = nil
 	}
 
+	cache := screen.displayFormatCache
+	screen.displayFormatCache = nil
+	screen.displayFormatFor = nil
+
+	glCopy := screen.glPixels
+	screen.glPixels = nil
+
 	screen.mutex.Unlock()
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if cache != nil {
+		cache.Free()
+	}
+	if glCopy != nil {
+		glCopy.Free()
+	}
 }
 
 // Locks a surface for direct access.
+//
+// Locks nest: a surface locked n times stays locked until Unlock has been
+// called n times, so a helper may lock a surface its caller already locked.
+// The lock depth belongs to the surface, not to a goroutine, and the mutex
+// of the surface is only held during the call, so Lock never blocks on
+// another Lock. It only makes the pixels accessible and doesn't exclude
+// other goroutines; goroutines sharing a surface must synchronize
+// their pixel access themselves.
 func (screen *Surface) Lock() int {
 	screen.mutex.Lock()
 	status := int(C.SDL_LockSurface(screen.cSurface))
+	if status == 0 {
+		screen.lockDepth++
+	}
 	screen.mutex.Unlock()
+
+	if status != 0 {
+		logError("Lock")
+	}
+
 	return status
 }
 
-// Unlocks a previously locked surface.
-func (screen *Surface) Unlock() {
-	screen.mutex.Lock()
-	C.SDL_UnlockSurface(screen.cSurface)
-	screen.mutex.Unlock()
+// Unlocks a previously locked surface. The surface stays locked until
+// every Lock has been matched by an Unlock.
+//
+// Unlocking a surface that isn't locked is a bug in the caller, typically
+// an Unlock after a failed Lock. It leaves the surface alone, sets the SDL
+// error and passes it to the logger (see SetLogger).
+func (screen *Surface) Unlock() {
+	screen.mutex.Lock()
+	if screen.lockDepth == 0 {
+		screen.mutex.Unlock()
+		SetError("the surface isn't locked")
+		logError("Unlock")
+		return
+	}
+	screen.lockDepth--
+	C.SDL_UnlockSurface(screen.cSurface)
+	screen.mutex.Unlock()
+}
+
+// Tells DisplayFormatCached that the pixels of the surface have changed,
+// for changes it can't notice itself: writes through the Pixels pointer
+// or Pixel32 after Lock, or drawing with the functions of package gfx.
+func (s *Surface) MarkModified() {
+	s.mutex.Lock()
+	s.modified = true
+	s.mutex.Unlock()
+}
+
+// Reports whether the surface must be locked before its pixels are accessed.
+func (s *Surface) mustLock() bool {
+	s.mutex.RLock()
+	must := C.__SDL_MUSTLOCK(s.cSurface) != 0
+	s.mutex.RUnlock()
+	return must
+}
+
+// Returns how many times the surface is currently locked (see Lock).
+func (screen *Surface) LockDepth() int {
+	screen.mutex.RLock()
+	depth := screen.lockDepth
+	screen.mutex.RUnlock()
+	return depth
+}
+
+// Performs a fast blit from the source surface to the destination surface.
+// This is the same as func BlitSurface, but the order of arguments is reversed.
+func (dst *Surface) Blit(dstrect *Rect, src *Surface, srcrect *Rect) int {
+	if src.getBlendMode() == BlendAdd {
+		return dst.BlitAdditive(dstrect, src, srcrect)
+	}
+
+	lockGlobal()
+	global := true
+	if (src != currentVideoSurface) && (dst != currentVideoSurface) {
+		unlockGlobal()
+		global = false
+	}
+
+	// At this point: GlobalMutex is locked only if at least one of 'src' or 'dst'
+	//                was identical to 'currentVideoSurface'
+
+	var ret C.int
+	{
+		src.mutex.RLock()
+		dst.mutex.Lock()
+		dst.modified = true
+
+		ret = C.SDL_UpperBlit(
+			src.cSurface,
+			(*C.SDL_Rect)(cast(srcrect)),
+			dst.cSurface,
+			(*C.SDL_Rect)(cast(dstrect)))
+
+		dst.mutex.Unlock()
+		src.mutex.RUnlock()
+	}
+
+	if global {
+		unlockGlobal()
+	}
+
+	if ret != 0 {
+		logError("Blit")
+	}
+
+	return int(ret)
+}
+
+// Same as Blit, but returns the area of dst that was actually blitted to,
+// after clipping against the source and the clip rectangle of dst.
+// The rectangle is empty if nothing was blitted. Unlike Blit,
+// this doesn't modify dstrect. A nil dstrect blits to (0, 0).
+func (dst *Surface) BlitClipped(dstrect *Rect, src *Surface, srcrect *Rect) (Rect, int) {
+	var clipped Rect
+	if dstrect != nil {
+		clipped = *dstrect
+	}
+
+	status := dst.Blit(&clipped, src, srcrect)
+	if status != 0 {
+		return Rect{}, status
+	}
+
+	return clipped, status
+}
+
+// A single blit of a batch, see BlitBatch.
+type BlitOp struct {
+	Src     *Surface
+	SrcRect *Rect
+	DstRect *Rect
+}
+
+// Performs several blits to the surface, like calling Blit for each
+// operation in turn, but takes the mutexes only once for the whole batch.
+// This noticeably reduces the overhead of drawing many small sprites,
+// such as the tiles of a map. Returns the status of each blit.
+//
+// Like Blit, the sources with BlendAdd (see SetBlendMode) are blitted with
+// BlitAdditive, for which the mutexes are released.
+func (dst *Surface) BlitBatch(ops []BlitOp) []int {
+	statuses := make([]int, len(ops))
+
+	lockGlobal()
+	global := dst == currentVideoSurface
+	for i := 0; i < len(ops) && !global; i++ {
+		global = ops[i].Src == currentVideoSurface
+	}
+	if !global {
+		unlockGlobal()
+	}
+
+	// At this point: GlobalMutex is locked only if at least one of the surfaces
+	//                was identical to 'currentVideoSurface'
+
+	dst.mutex.Lock()
+	dst.modified = true
+	for i, op := range ops {
+		// dst.mutex is already locked, so the mode of dst is read directly
+		mode := dst.blendMode
+		if op.Src != dst {
+			mode = op.Src.getBlendMode()
+		}
+
+		if mode == BlendAdd {
+			dst.mutex.Unlock()
+			if global {
+				unlockGlobal()
+			}
+
+			statuses[i] = dst.BlitAdditive(op.DstRect, op.Src, op.SrcRect)
+
+			if global {
+				lockGlobal()
+			}
+			dst.mutex.Lock()
+			continue
+		}
+
+		if op.Src != dst {
+			op.Src.mutex.RLock()
+		}
+
+		statuses[i] = int(C.SDL_UpperBlit(
+			op.Src.cSurface,
+			(*C.SDL_Rect)(cast(op.SrcRect)),
+			dst.cSurface,
+			(*C.SDL_Rect)(cast(op.DstRect))))
+
+		if op.Src != dst {
+			op.Src.mutex.RUnlock()
+		}
+	}
+	dst.mutex.Unlock()
+
+	if global {
+		unlockGlobal()
+	}
+
+	return statuses
 }
 
 // Performs a fast blit from the source surface to the destination surface.
-// This is the same as func BlitSurface, but the order of arguments is reversed.
-func (dst *Surface) Blit(dstrect *Rect, src *Surface, srcrect *Rect) int {
-	GlobalMutex.Lock()
+func BlitSurface(src *Surface, srcrect *Rect, dst *Surface, dstrect *Rect) int {
+	return dst.Blit(dstrect, src, srcrect)
+}
+
+// Performs a nearest-neighbor stretch blit from the source surface to the
+// destination surface (using SDL_SoftStretch). Unlike Zoom, no smoothing is
+// applied, which keeps pixel art crisp.
+//
+// SDL imposes some restrictions: both surfaces must have the same pixel format
+// (convert one of them first, for example with DisplayFormat), and the rectangles
+// are not clipped, they must lie completely within their surfaces.
+// A nil rect means the whole surface. Returns 0 on success, -1 on error.
+func (dst *Surface) SoftStretch(dstrect *Rect, src *Surface, srcrect *Rect) int {
+	lockGlobal()
 	global := true
 	if (src != currentVideoSurface) && (dst != currentVideoSurface) {
-		GlobalMutex.Unlock()
+		unlockGlobal()
 		global = false
 	}
 
-	// At this point: GlobalMutex is locked only if at least one of 'src' or 'dst'
-	//                was identical to 'currentVideoSurface'
-
 	var ret C.int
 	{
 		src.mutex.RLock()
 		dst.mutex.Lock()
+		dst.modified = true
 
-		ret = C.SDL_UpperBlit(
+		ret = C.SDL_SoftStretch(
 			src.cSurface,
 			(*C.SDL_Rect)(cast(srcrect)),
 			dst.cSurface,
@@ -478,20 +1367,16 @@ func (dst *Surface) Blit(dstrect *Rect, src *Surface, srcrect *Rect) int {
 	}
 
 	if global {
-		GlobalMutex.Unlock()
+		unlockGlobal()
 	}
 
 	return int(ret)
 }
 
-// Performs a fast blit from the source surface to the destination surface.
-func BlitSurface(src *Surface, srcrect *Rect, dst *Surface, dstrect *Rect) int {
-	return dst.Blit(dstrect, src, srcrect)
-}
-
 // This function performs a fast fill of the given rectangle with some color.
 func (dst *Surface) FillRect(dstrect *Rect, color uint32) int {
 	dst.mutex.Lock()
+	dst.modified = true
 
 	var ret = C.SDL_FillRect(
 		dst.cSurface,
@@ -500,23 +1385,129 @@ func (dst *Surface) FillRect(dstrect *Rect, color uint32) int {
 
 	dst.mutex.Unlock()
 
+	if ret != 0 {
+		logError("FillRect")
+	}
+
 	return int(ret)
 }
 
 // Adjusts the alpha properties of a Surface.
 func (s *Surface) SetAlpha(flags uint32, alpha uint8) int {
 	s.mutex.Lock()
+	s.modified = true
 	status := int(C.SDL_SetAlpha(s.cSurface, C.Uint32(flags), C.Uint8(alpha)))
+	s.Flags = uint32(s.cSurface.flags)
 	s.mutex.Unlock()
+
+	if status != 0 {
+		logError("SetAlpha")
+	}
+
 	return status
 }
 
+// Sets the per-surface alpha and enables or disables alpha blending,
+// leaving RLE acceleration as it is. Returns the previous settings,
+// so that they can be restored later.
+func (s *Surface) SetAlphaMod(alpha uint8, enabled bool) (prevAlpha uint8, prevEnabled bool, err error) {
+	s.mutex.Lock()
+	s.modified = true
+
+	prevAlpha = s.Format.Alpha
+	prevEnabled = s.cSurface.flags&SRCALPHA != 0
+
+	var flags C.Uint32
+	if enabled {
+		flags |= SRCALPHA
+	}
+	if s.cSurface.flags&RLEACCELOK != 0 {
+		flags |= RLEACCEL
+	}
+
+	status := C.SDL_SetAlpha(s.cSurface, flags, C.Uint8(alpha))
+	s.Flags = uint32(s.cSurface.flags)
+
+	s.mutex.Unlock()
+
+	if status != 0 {
+		err = errors.New(GetError())
+	}
+	return
+}
+
 // Sets the color key (transparent pixel)  in  a  blittable  surface  and
 // enables or disables RLE blit acceleration.
 func (s *Surface) SetColorKey(flags uint32, ColorKey uint32) int {
 	s.mutex.Lock()
+	s.modified = true
 	status := int(C.SDL_SetColorKey(s.cSurface, C.Uint32(flags), C.Uint32(ColorKey)))
+	s.Flags = uint32(s.cSurface.flags)
+	s.mutex.Unlock()
+
+	if status != 0 {
+		logError("SetColorKey")
+	}
+
+	return status
+}
+
+// Enables or disables RLE acceleration for blits from the surface, keeping
+// the colorkey and alpha settings. RLE only applies to surfaces with a colorkey
+// or per-surface alpha, so enabling it fails (returning -1) on other surfaces.
+//
+// RLE-encoded surfaces blit much faster, especially sprites with large
+// transparent areas, but the surface is encoded at the next blit and has to be
+// decoded again whenever it is locked. Use it for surfaces that rarely change.
+func (s *Surface) SetRLE(enabled bool) int {
+	var rle C.Uint32
+	if enabled {
+		rle = RLEACCEL
+	}
+
+	s.mutex.Lock()
+
+	var status C.int
+	switch {
+	case s.cSurface.flags&SRCCOLORKEY != 0:
+		status = C.SDL_SetColorKey(s.cSurface, SRCCOLORKEY|rle, C.Uint32(s.Format.Colorkey))
+	case s.cSurface.flags&SRCALPHA != 0:
+		status = C.SDL_SetAlpha(s.cSurface, SRCALPHA|rle, C.Uint8(s.Format.Alpha))
+	case enabled:
+		s.mutex.Unlock()
+		SetError("SetRLE: the surface has neither a colorkey nor per-surface alpha")
+		return -1
+	}
+	s.Flags = uint32(s.cSurface.flags)
+
+	s.mutex.Unlock()
+
+	return int(status)
+}
+
+// Sets a portion of the palette of an 8-bit surface, starting at firstcolor.
+// Returns 1 if all colors were set as requested, 0 otherwise.
+func (s *Surface) SetColors(colors []Color, firstcolor int) int {
+	if len(colors) == 0 {
+		return 1
+	}
+
+	lockGlobal()
+	global := true
+	if s != currentVideoSurface {
+		unlockGlobal()
+		global = false
+	}
+
+	s.mutex.Lock()
+	s.modified = true
+	status := int(C.SDL_SetColors(s.cSurface, (*C.SDL_Color)(cast(&colors[0])), C.int(firstcolor), C.int(len(colors))))
 	s.mutex.Unlock()
+
+	if global {
+		unlockGlobal()
+	}
+
 	return status
 }
 
@@ -551,6 +1542,8 @@ var ExpandByte [9][]uint32 = [9][]uint32 {
 }
 
 // Map a RGBA color value to a pixel format.
+// Returns 0 and sets the SDL error if the format is nil,
+// as is the format of a freed surface.
 //
 // You can do the pixel mapping in inner loops with the
 // code shown in the function below. (Except palette handling)
@@ -565,14 +1558,23 @@ var ExpandByte [9][]uint32 = [9][]uint32 {
 // 	return sdl.MapRGBA(format, r,g,b,a)
 // }
 func MapRGBA(format *PixelFormat, r, g, b, a uint8) uint32 {
+	if format == nil {
+		SetError("MapRGBA: nil pixel format")
+		return 0
+	}
 	return (uint32)(C.SDL_MapRGBA((*C.SDL_PixelFormat)(cast(format)), (C.Uint8)(r), (C.Uint8)(g), (C.Uint8)(b), (C.Uint8)(a)))
 }
 
 func MapRGB(format *PixelFormat, r, g, b uint8) uint32 {
+	if format == nil {
+		SetError("MapRGB: nil pixel format")
+		return 0
+	}
 	return (uint32)(C.SDL_MapRGB((*C.SDL_PixelFormat)(cast(format)), (C.Uint8)(r), (C.Uint8)(g), (C.Uint8)(b)))
 }
 
 // Functionally the inverse of MapRGBA. The same performance considerations apply.
+// If the format is nil, all components are 0 and the SDL error is set.
 //
 // func GetRGBA(color uint32, format *PixelFormat, r, g, b, a *uint8) {
 //     if (format.Palette == nil) {
@@ -590,13 +1592,45 @@ func MapRGB(format *PixelFormat, r, g, b uint8) uint32 {
 //     }
 // }
 func GetRGB(color uint32, format *PixelFormat, r, g, b *uint8) {
+	if format == nil {
+		SetError("GetRGB: nil pixel format")
+		*r, *g, *b = 0, 0, 0
+		return
+	}
 	C.SDL_GetRGB(C.Uint32(color), (*C.SDL_PixelFormat)(cast(format)), (*C.Uint8)(r), (*C.Uint8)(g), (*C.Uint8)(b))
 }
 
 func GetRGBA(color uint32, format *PixelFormat, r, g, b, a *uint8) {
+	if format == nil {
+		SetError("GetRGBA: nil pixel format")
+		*r, *g, *b, *a = 0, 0, 0, 0
+		return
+	}
 	C.SDL_GetRGBA(C.Uint32(color), (*C.SDL_PixelFormat)(cast(format)), (*C.Uint8)(r), (*C.Uint8)(g), (*C.Uint8)(b), (*C.Uint8)(a))
 }
 
+// Maps a RGBA color value to the pixel format of the surface.
+func (s *Surface) MapRGBA(r, g, b, a uint8) uint32 {
+	return MapRGBA(s.Format, r, g, b, a)
+}
+
+// Maps a RGB color value to the pixel format of the surface.
+func (s *Surface) MapRGB(r, g, b uint8) uint32 {
+	return MapRGB(s.Format, r, g, b)
+}
+
+// Gets the RGBA components of a pixel in the pixel format of the surface.
+func (s *Surface) GetRGBA(pixel uint32) (r, g, b, a uint8) {
+	GetRGBA(pixel, s.Format, &r, &g, &b, &a)
+	return
+}
+
+// Gets the RGB components of a pixel in the pixel format of the surface.
+func (s *Surface) GetRGB(pixel uint32) (r, g, b uint8) {
+	GetRGB(pixel, s.Format, &r, &g, &b)
+	return
+}
+
 // Access the pixels of a 4 byte per pixel surface as []uint32.
 //
 // BUG(Zwobot) Pixel 32 doesn't handle surfaces with an offset or pitch not aligned to uint32.
@@ -608,38 +1642,75 @@ func (s *Surface) Pixel32() []uint32 {
 
 // Loads Surface from file (using IMG_Load).
 func Load(file string) *Surface {
-	GlobalMutex.Lock()
+	lockGlobal()
 
 	cfile := C.CString(file)
 	var screen = C.IMG_Load(cfile)
 	C.free(unsafe.Pointer(cfile))
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if screen == nil {
+		logError("Load")
+	}
+
+	return wrap(screen).account(false)
+}
+
+// Loads a Windows BMP image from memory. This uses SDL itself,
+// so it works without SDL_image and its optional codecs,
+// for example for fallback assets embedded in the program.
+func LoadBMP(data []byte) *Surface {
+	if len(data) == 0 {
+		return nil
+	}
+
+	// The data is copied to C memory, which stays in place while SDL reads it
+	cdata := C.CBytes(data)
 
-	return wrap(screen)
+	lockGlobal()
+	rw := C.SDL_RWFromConstMem(cdata, C.int(len(data)))
+	var p *C.SDL_Surface
+	if rw != nil {
+		p = C.SDL_LoadBMP_RW(rw, 1) // Frees rw
+	}
+	unlockGlobal()
+
+	C.free(cdata)
+
+	return wrap(p).account(false)
 }
 
 // SaveBMP saves the src surface as a Windows BMP to file.
 func (src *Surface) SaveBMP(file string) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	cfile := C.CString(file)
 	// SDL_SaveBMP is a macro.
 	res := int(C.__SDL_SaveBMP(src.cSurface, cfile))
 	C.free(unsafe.Pointer(cfile))
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if res != 0 {
+		logError("SaveBMP")
+	}
+
 	return res
 }
 
 // Creates an empty Surface.
 func CreateRGBSurface(flags uint32, width int, height int, bpp int, Rmask uint32, Gmask uint32, Bmask uint32, Amask uint32) *Surface {
-	GlobalMutex.Lock()
+	lockGlobal()
 
 	p := C.SDL_CreateRGBSurface(C.Uint32(flags), C.int(width), C.int(height), C.int(bpp),
 		C.Uint32(Rmask), C.Uint32(Gmask), C.Uint32(Bmask), C.Uint32(Amask))
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
+
+	if p == nil {
+		logError("CreateRGBSurface")
+	}
 
-	return wrap(p)
+	return wrap(p).account(false)
 }
 
 // Creates a Surface from existing pixel data. It expects pixels to be a slice, pointer or unsafe.Pointer.
@@ -652,13 +1723,15 @@ func CreateRGBSurfaceFrom(pixels interface{}, width, height, bpp, pitch int, Rma
 		panic("Don't know how to handle type: " + v.Kind().String())
 	}
 
-	GlobalMutex.Lock()
+	lockGlobal()
 	p := C.SDL_CreateRGBSurfaceFrom(ptr, C.int(width), C.int(height), C.int(bpp), C.int(pitch),
 		C.Uint32(Rmask), C.Uint32(Gmask), C.Uint32(Bmask), C.Uint32(Amask))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 
-	s := wrap(p)
-	s.gcPixels = pixels
+	s := wrap(p).account(true)
+	if s != nil {
+		s.gcPixels = pixels
+	}
 	return s
 }
 
@@ -667,7 +1740,48 @@ func (s *Surface) DisplayFormat() *Surface {
 	s.mutex.RLock()
 	p := C.SDL_DisplayFormat(s.cSurface)
 	s.mutex.RUnlock()
-	return wrap(p)
+	return wrap(p).account(false)
+}
+
+// Returns the surface converted to the display format, like DisplayFormat,
+// but keeps the converted surface and returns it again on the next call,
+// so a sprite drawn every frame is converted only once. Returns nil on error.
+//
+// The surface is converted again if the video mode was set since
+// (by SetVideoMode or ToggleFullScreen), or if the surface may have been
+// modified by a function of this package: FillRect, Blit or SoftStretch
+// to it, the drawing functions such as DrawLine or FloodFill, EachRow,
+// SetColors, SetColorKey or SetAlpha. Reading the pixels, for example with
+// Histogram, doesn't count. Changes made in any other way, such as writes
+// through the Pixels pointer, must be reported with MarkModified.
+//
+// The converted surface belongs to s. It must not be freed or modified,
+// it is freed when s is freed or converted again.
+func (s *Surface) DisplayFormatCached() *Surface {
+	screen := GetVideoSurface()
+
+	s.mutex.Lock()
+	cache := s.displayFormatCache
+	if cache != nil && !s.modified && s.displayFormatFor == screen {
+		s.mutex.Unlock()
+		return cache
+	}
+	s.displayFormatCache = nil
+	s.modified = false // Modifications from now on are noticed by the next call
+	s.mutex.Unlock()
+
+	if cache != nil {
+		cache.Free()
+	}
+
+	converted := s.DisplayFormat().owned()
+
+	s.mutex.Lock()
+	s.displayFormatCache = converted
+	s.displayFormatFor = screen
+	s.mutex.Unlock()
+
+	return converted
 }
 
 // Converts a surface to the display format with alpha
@@ -675,7 +1789,25 @@ func (s *Surface) DisplayFormatAlpha() *Surface {
 	s.mutex.RLock()
 	p := C.SDL_DisplayFormatAlpha(s.cSurface)
 	s.mutex.RUnlock()
-	return wrap(p)
+	return wrap(p).account(false)
+}
+
+// Creates an independent copy of a surface, with the same size, pixel format
+// (including the palette), pixels, colorkey and alpha settings.
+// Returns nil on error.
+func (s *Surface) Clone() *Surface {
+	lockGlobal() // Because 'C.SDL_ConvertSurface' uses 'C.SDL_CreateRGBSurface'
+	s.mutex.RLock()
+
+	// SDL_ConvertSurface copies the pixels without blending, then applies
+	// the colorkey and alpha settings selected by these flags to the copy
+	flags := C.Uint32(s.Flags & (HWSURFACE | SRCCOLORKEY | SRCALPHA | RLEACCELOK))
+	p := C.SDL_ConvertSurface(s.cSurface, s.cSurface.format, flags)
+
+	s.mutex.RUnlock()
+	unlockGlobal()
+
+	return wrap(p).account(false)
 }
 
 // ========
@@ -684,17 +1816,17 @@ func (s *Surface) DisplayFormatAlpha() *Surface {
 
 // Enables UNICODE translation.
 func EnableUNICODE(enable int) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	previous := int(C.SDL_EnableUNICODE(C.int(enable)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return previous
 }
 
 // Sets keyboard repeat rate.
 func EnableKeyRepeat(delay, interval int) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	status := int(C.SDL_EnableKeyRepeat(C.int(delay), C.int(interval)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return status
 }
 
@@ -703,28 +1835,58 @@ func GetKeyRepeat() (int, int) {
 	var delay int
 	var interval int
 
-	GlobalMutex.Lock()
+	lockGlobal()
 	C.SDL_GetKeyRepeat((*C.int)(cast(&delay)), (*C.int)(cast(&interval)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 
 	return delay, interval
 }
 
-// Gets a snapshot of the current keyboard state
+// Gets the current keyboard state, indexed by Key.
+//
+// NOTE: Despite the name, the result is not a snapshot. The slice aliases
+// SDL's internal array, which is updated whenever events are pumped
+// (this happens continuously in the background, see Events).
+// Use KeyStateSnapshot for a copy that doesn't change under the caller.
+//
+// Returns nil if the video subsystem isn't initialized.
 func GetKeyState() []uint8 {
-	GlobalMutex.Lock()
+	lockGlobal()
+	if !videoInitialized("GetKeyState") {
+		unlockGlobal()
+		return nil
+	}
 
 	var numkeys C.int
 	array := C.SDL_GetKeyState(&numkeys)
 
-	var ptr = make([]uint8, numkeys)
+	// Build the slice header directly, the array is owned by SDL
+	header := reflect.SliceHeader{uintptr(unsafe.Pointer(array)), int(numkeys), int(numkeys)}
+	state := *(*[]uint8)(unsafe.Pointer(&header))
+
+	unlockGlobal()
 
-	*((**C.Uint8)(unsafe.Pointer(&ptr))) = array // TODO
+	return state
+}
+
+// Gets a copy of the current keyboard state, indexed by Key.
+// Unlike the result of GetKeyState, the copy stays the same
+// while SDL processes new events. Returns nil if the video subsystem
+// isn't initialized.
+func KeyStateSnapshot() []uint8 {
+	lockGlobal()
+	if !videoInitialized("KeyStateSnapshot") {
+		unlockGlobal()
+		return nil
+	}
 
-	GlobalMutex.Unlock()
+	var numkeys C.int
+	array := C.SDL_GetKeyState(&numkeys)
+	state := C.GoBytes(unsafe.Pointer(array), numkeys)
 
-	return ptr
+	unlockGlobal()
 
+	return state
 }
 
 // Modifier
@@ -735,24 +1897,24 @@ type Key C.int
 
 // Gets the state of modifier keys
 func GetModState() Mod {
-	GlobalMutex.Lock()
+	lockGlobal()
 	state := Mod(C.SDL_GetModState())
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return state
 }
 
 // Sets the state of modifier keys
 func SetModState(modstate Mod) {
-	GlobalMutex.Lock()
+	lockGlobal()
 	C.SDL_SetModState(C.SDLMod(modstate))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 }
 
 // Gets the name of an SDL virtual keysym
 func GetKeyName(key Key) string {
-	GlobalMutex.Lock()
+	lockGlobal()
 	name := C.GoString(C.SDL_GetKeyName(C.SDLKey(key)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return name
 }
 
@@ -762,50 +1924,255 @@ func GetKeyName(key Key) string {
 
 // Polls for currently pending events
 func (event *Event) poll() bool {
-	GlobalMutex.Lock()
+	lockGlobal()
 
 	var ret = C.SDL_PollEvent((*C.SDL_Event)(cast(event)))
 
-	if ret != 0 {
-		if (event.Type == VIDEORESIZE) && (currentVideoSurface != nil) {
+	if (ret != 0) && (event.Type == VIDEORESIZE) {
+		e := (*ResizeEvent)(cast(event))
+		resizedFromW, resizedFromH = windowW, windowH
+		windowW, windowH = e.W, e.H
+
+		if currentVideoSurface != nil {
 			currentVideoSurface.reload()
 		}
+		resizePending = true
 	}
 
-	GlobalMutex.Unlock()
+	unlockGlobal()
 
 	return ret != 0
 }
 
+// The size of the window as last seen, set by SetVideoMode and by each
+// VIDEORESIZE event polled, and the size before the last VIDEORESIZE event
+// (see Event.Resize). Protected by GlobalMutex.
+var windowW, windowH, resizedFromW, resizedFromH int32
+
+// The function set by OnResize, and whether a VIDEORESIZE event was polled
+// that it hasn't been called for yet. Protected by GlobalMutex.
+var (
+	resizeListener func(w, h int)
+	resizePending  bool
+)
+
+// Sets a function to call with the new size whenever the window is resized
+// (a VIDEORESIZE event is polled), the central place to recompute the
+// projection and call glViewport. Replaces the previous function,
+// nil removes it.
+//
+// The function isn't called by the goroutine that polls the event, which is
+// usually the background goroutine behind Events, but by the next of these
+// calls made by the application, so that it runs on the goroutine doing
+// the rendering, where OpenGL calls may be made:
+//
+//   - PollEvent (and WaitEventTimeout), right after polling the event
+//   - EventDispatcher.Dispatch (and EventDispatcher.Run), before the handlers
+//     of the VideoResizeEvent
+//   - Run, before calling update
+//   - SetVideoMode, after setting the video mode
+//
+// The video surface has been reloaded by then. If the window was resized
+// several times in the meantime, the function is called once, with the
+// latest size.
+func OnResize(listener func(w, h int)) {
+	lockGlobal()
+	resizeListener = listener
+	unlockGlobal()
+}
+
+// Calls the function set by OnResize if a VIDEORESIZE event was polled since
+// the last call. Must be called without holding GlobalMutex.
+func notifyResize() {
+	lockGlobal()
+	listener := resizeListener
+	pending := resizePending
+	resizePending = false
+	w, h := windowW, windowH
+	unlockGlobal()
+
+	if pending && listener != nil {
+		listener(int(w), int(h))
+	}
+}
+
+// Takes the next pending event off the queue if it is a MOUSEMOTION event.
+// Returns false if the queue is empty or starts with another event.
+// The event is looked at and removed while holding GlobalMutex,
+// so no other poll can take it in between.
+func (event *Event) pollMotion() bool {
+	e := (*C.SDL_Event)(cast(event))
+	taken := false
+
+	lockGlobal()
+	if C.SDL_PeepEvents(e, 1, C.SDL_PEEKEVENT, C.SDL_ALLEVENTS) > 0 && event.Type == MOUSEMOTION {
+		// The first motion event in the queue is the one just seen
+		taken = C.SDL_PeepEvents(e, 1, C.SDL_GETEVENT, C.SDL_MOUSEMOTIONMASK) > 0
+	}
+	unlockGlobal()
+
+	return taken
+}
+
+// Polls for a currently pending event. Returns false if there is none.
+//
+// Normally events are polled by a background goroutine and delivered
+// by Events, so there is nothing left to poll here. This is meant for
+// single-threaded mode (see SetSingleThreaded), in which that goroutine
+// is stopped. The event can be converted like the values from Events
+// with EventDispatcher.Dispatch.
+func PollEvent(event *Event) bool {
+	ok := event.poll()
+	notifyResize()
+	return ok
+}
+
+// Gathers pending input from the devices and updates the state returned by
+// GetKeyState, GetMouseState and the like, without taking events off the queue.
+//
+// The goroutine behind Events does this every few milliseconds, so it is
+// mostly needed in single-threaded mode (see SetSingleThreaded). Like the
+// other video functions, it must be called from the thread that initialized
+// the video subsystem on platforms that require it.
+// Returns 0 on success, -1 if the video subsystem isn't initialized.
+func PumpEvents() int {
+	lockGlobal()
+	if !videoInitialized("PumpEvents") {
+		unlockGlobal()
+		return -1
+	}
+	C.SDL_PumpEvents()
+	unlockGlobal()
+	return 0
+}
+
+// Pushes an event onto the event queue. The event is delivered by Events
+// like any other event. Returns 0 on success, -1 if the queue is full
+// or the video subsystem (which contains the event queue) isn't initialized.
+func PushEvent(event *Event) int {
+	lockGlobal()
+	if !videoInitialized("PushEvent") {
+		unlockGlobal()
+		return -1
+	}
+	status := int(C.SDL_PushEvent((*C.SDL_Event)(cast(event))))
+	unlockGlobal()
+	return status
+}
+
+// Gets the state of the application window, a combination of APPMOUSEFOCUS,
+// APPINPUTFOCUS and APPACTIVE. APPACTIVE is cleared while the window
+// is minimized (iconified).
+func GetAppState() uint8 {
+	lockGlobal()
+	state := uint8(C.SDL_GetAppState())
+	unlockGlobal()
+	return state
+}
+
 // =====
 // Mouse
 // =====
 
 // Retrieves the current state of the mouse.
 func GetMouseState(x, y *int) uint8 {
-	GlobalMutex.Lock()
-	state := uint8(C.SDL_GetMouseState((*C.int)(cast(x)), (*C.int)(cast(y))))
-	GlobalMutex.Unlock()
+	// SDL writes C ints, which are smaller than Go ints on 64-bit platforms
+	var cx, cy C.int
+
+	lockGlobal()
+	state := uint8(C.SDL_GetMouseState(&cx, &cy))
+	unlockGlobal()
+
+	if x != nil {
+		*x = int(cx)
+	}
+	if y != nil {
+		*y = int(cy)
+	}
 	return state
 }
 
 // Retrieves the current state of the mouse relative to the last time this
 // function was called.
 func GetRelativeMouseState(x, y *int) uint8 {
-	GlobalMutex.Lock()
-	state := uint8(C.SDL_GetRelativeMouseState((*C.int)(cast(x)), (*C.int)(cast(y))))
-	GlobalMutex.Unlock()
+	var cx, cy C.int
+
+	lockGlobal()
+	state := uint8(C.SDL_GetRelativeMouseState(&cx, &cy))
+	unlockGlobal()
+
+	if x != nil {
+		*x = int(cx)
+	}
+	if y != nil {
+		*y = int(cy)
+	}
 	return state
 }
 
+// Moves the mouse cursor to the given position in the window,
+// generating a MOUSEMOTION event.
+// Returns 0 on success, -1 if the video subsystem isn't initialized.
+func WarpMouse(x, y int) int {
+	lockGlobal()
+	if !videoInitialized("WarpMouse") {
+		unlockGlobal()
+		return -1
+	}
+	C.SDL_WarpMouse(C.Uint16(x), C.Uint16(y))
+	unlockGlobal()
+	return 0
+}
+
 // Toggle whether or not the cursor is shown on the screen.
 func ShowCursor(toggle int) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	state := int(C.SDL_ShowCursor((C.int)(toggle)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return state
 }
 
+var relativeMouse = false
+var relativeMousePrevGrab C.SDL_GrabMode
+var relativeMousePrevCursor C.int
+
+// Enables or disables relative mouse mode, the mode wanted by
+// mouse-look camera code: input is grabbed and the cursor is hidden,
+// so that MOUSEMOTION events keep reporting relative motion (Xrel, Yrel)
+// even when the cursor would have hit the edge of the window.
+//
+// Disabling restores the grab mode and cursor visibility
+// that were in effect when the mode was enabled.
+func SetRelativeMouseMode(enabled bool) {
+	lockGlobal()
+
+	if enabled && !relativeMouse {
+		relativeMousePrevGrab = C.SDL_WM_GrabInput(C.SDL_GRAB_QUERY)
+		relativeMousePrevCursor = C.SDL_ShowCursor(QUERY)
+
+		C.SDL_WM_GrabInput(C.SDL_GRAB_ON)
+		C.SDL_ShowCursor(DISABLE)
+
+		// Discard the motion accumulated so far, so that the first
+		// relative reading doesn't jump
+		C.SDL_GetRelativeMouseState(nil, nil)
+	} else if !enabled && relativeMouse {
+		C.SDL_WM_GrabInput(relativeMousePrevGrab)
+		C.SDL_ShowCursor(relativeMousePrevCursor)
+	}
+	relativeMouse = enabled
+
+	unlockGlobal()
+}
+
+// Reports whether relative mouse mode is enabled.
+func GetRelativeMouseMode() bool {
+	lockGlobal()
+	enabled := relativeMouse
+	unlockGlobal()
+	return enabled
+}
+
 // ========
 // Joystick
 // ========
@@ -826,22 +2193,80 @@ func wrapJoystick(cJoystick *C.SDL_Joystick) *Joystick {
 	return j
 }
 
+// The joysticks opened by JoystickOpen and not closed yet, for Shutdown.
+// Protected by GlobalMutex.
+var openJoysticks = make(map[*Joystick]struct{})
+
 // Count the number of joysticks attached to the system
 func NumJoysticks() int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	num := int(C.SDL_NumJoysticks())
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return num
 }
 
+// Names of the joysticks seen by the last call to PollJoysticks,
+// by device index. Protected by GlobalMutex.
+var knownJoysticks []string
+
+// Enumerates the attached joysticks again and returns the device indices that
+// have appeared or disappeared since the previous call. A device index whose
+// name (see JoystickName) has changed is reported as removed and added, since
+// another controller took its place. The first call reports all attached
+// joysticks as added.
+//
+// SDL 1.2 enumerates the devices only when the joystick subsystem is
+// initialized, so PollJoysticks re-initializes it, but only while no joystick
+// opened with JoystickOpen is open, since that would close them. While some
+// are open, it compares with the enumeration of SDL as it is, which reports
+// nothing new. Polling is slow, call it when the player asks for it or every
+// few seconds, not every frame.
+func PollJoysticks() (added, removed []int) {
+	lockGlobal()
+
+	if C.SDL_WasInit(C.SDL_INIT_JOYSTICK) != 0 && len(openJoysticks) == 0 {
+		C.SDL_QuitSubSystem(C.SDL_INIT_JOYSTICK)
+		C.SDL_InitSubSystem(C.SDL_INIT_JOYSTICK)
+	}
+
+	names := make([]string, int(C.SDL_NumJoysticks()))
+	for i := range names {
+		if cName := C.SDL_JoystickName(C.int(i)); cName != nil {
+			names[i] = C.GoString(cName)
+		}
+	}
+
+	for i := 0; i < len(names) || i < len(knownJoysticks); i++ {
+		switch {
+		case i >= len(knownJoysticks):
+			added = append(added, i)
+		case i >= len(names):
+			removed = append(removed, i)
+		case names[i] != knownJoysticks[i]:
+			removed = append(removed, i)
+			added = append(added, i)
+		}
+	}
+	knownJoysticks = names
+
+	unlockGlobal()
+
+	return
+}
+
 // Get the implementation dependent name of a joystick.
 // This can be called before any joysticks are opened.
-// If no name can be found, this function returns NULL.
-func JoystickName(deviceIndex int) string {
-	GlobalMutex.Lock()
-	name := C.GoString(C.SDL_JoystickName(C.int(deviceIndex)))
-	GlobalMutex.Unlock()
-	return name
+// Returns ok=false if the index is out of range (see NumJoysticks)
+// or no name can be found.
+func JoystickName(deviceIndex int) (name string, ok bool) {
+	lockGlobal()
+	if deviceIndex >= 0 && deviceIndex < int(C.SDL_NumJoysticks()) {
+		if cName := C.SDL_JoystickName(C.int(deviceIndex)); cName != nil {
+			name, ok = C.GoString(cName), true
+		}
+	}
+	unlockGlobal()
+	return
 }
 
 // Open a joystick for use The index passed as an argument refers to
@@ -849,26 +2274,29 @@ func JoystickName(deviceIndex int) string {
 // identify this joystick in future joystick events.  This function
 // returns a joystick identifier, or NULL if an error occurred.
 func JoystickOpen(deviceIndex int) *Joystick {
-	GlobalMutex.Lock()
-	joystick := C.SDL_JoystickOpen(C.int(deviceIndex))
-	GlobalMutex.Unlock()
-	return wrapJoystick(joystick)
+	lockGlobal()
+	joystick := wrapJoystick(C.SDL_JoystickOpen(C.int(deviceIndex)))
+	if joystick != nil {
+		openJoysticks[joystick] = struct{}{}
+	}
+	unlockGlobal()
+	return joystick
 }
 
 // Returns 1 if the joystick has been opened, or 0 if it has not.
 func JoystickOpened(deviceIndex int) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	opened := int(C.SDL_JoystickOpened(C.int(deviceIndex)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return opened
 }
 
 // Update the current state of the open joysticks. This is called
 // automatically by the event loop if any joystick events are enabled.
 func JoystickUpdate() {
-	GlobalMutex.Lock()
+	lockGlobal()
 	C.SDL_JoystickUpdate()
-	GlobalMutex.Unlock()
+	unlockGlobal()
 }
 
 // Enable/disable joystick event polling. If joystick events are
@@ -876,70 +2304,165 @@ func JoystickUpdate() {
 // state of the joystick when you want joystick information. The state
 // can be one of SDL_QUERY, SDL_ENABLE or SDL_IGNORE.
 func JoystickEventState(state int) int {
-	GlobalMutex.Lock()
+	lockGlobal()
 	result := int(C.SDL_JoystickEventState(C.int(state)))
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return result
 }
 
 // Close a joystick previously opened with SDL_JoystickOpen()
+//
+// Closing a joystick that is already closed (for example by Shutdown)
+// does nothing. The methods of a closed joystick set an error and return
+// zero values (-1 for the counts), like SDL does for joysticks never opened.
 func (joystick *Joystick) Close() {
-	GlobalMutex.Lock()
-	C.SDL_JoystickClose(joystick.cJoystick)
-	GlobalMutex.Unlock()
+	lockGlobal()
+	if _, open := openJoysticks[joystick]; open {
+		delete(openJoysticks, joystick)
+		C.SDL_JoystickClose(joystick.cJoystick)
+		joystick.cJoystick = nil
+	}
+	unlockGlobal()
 }
 
 // Get the number of general axis controls on a joystick
 func (joystick *Joystick) NumAxes() int {
-	return int(C.SDL_JoystickNumAxes(joystick.cJoystick))
+	lockGlobal()
+	result := int(C.SDL_JoystickNumAxes(joystick.cJoystick))
+	unlockGlobal()
+	return result
 }
 
 // Get the device index of an opened joystick.
 func (joystick *Joystick) Index() int {
-	return int(C.SDL_JoystickIndex(joystick.cJoystick))
+	lockGlobal()
+	result := int(C.SDL_JoystickIndex(joystick.cJoystick))
+	unlockGlobal()
+	return result
 }
 
 // Get the number of buttons on a joystick
 func (joystick *Joystick) NumButtons() int {
-	return int(C.SDL_JoystickNumButtons(joystick.cJoystick))
+	lockGlobal()
+	result := int(C.SDL_JoystickNumButtons(joystick.cJoystick))
+	unlockGlobal()
+	return result
 }
 
 // Get the number of trackballs on a Joystick trackballs have only
 // relative motion events associated with them and their state cannot
 // be polled.
 func (joystick *Joystick) NumBalls() int {
-	return int(C.SDL_JoystickNumBalls(joystick.cJoystick))
+	lockGlobal()
+	result := int(C.SDL_JoystickNumBalls(joystick.cJoystick))
+	unlockGlobal()
+	return result
 }
 
 // Get the number of POV hats on a joystick
 func (joystick *Joystick) NumHats() int {
-	return int(C.SDL_JoystickNumHats(joystick.cJoystick))
+	lockGlobal()
+	result := int(C.SDL_JoystickNumHats(joystick.cJoystick))
+	unlockGlobal()
+	return result
 }
 
 // Get the current state of a POV hat on a joystick
 // The hat indices start at index 0.
 func (joystick *Joystick) GetHat(hat int) uint8 {
-	return uint8(C.SDL_JoystickGetHat(joystick.cJoystick, C.int(hat)))
+	lockGlobal()
+	result := uint8(C.SDL_JoystickGetHat(joystick.cJoystick, C.int(hat)))
+	unlockGlobal()
+	return result
 }
 
 // Get the current state of a button on a joystick. The button indices
 // start at index 0.
 func (joystick *Joystick) GetButton(button int) uint8 {
-	return uint8(C.SDL_JoystickGetButton(joystick.cJoystick, C.int(button)))
+	lockGlobal()
+	result := uint8(C.SDL_JoystickGetButton(joystick.cJoystick, C.int(button)))
+	unlockGlobal()
+	return result
 }
 
 // Get the ball axis change since the last poll. The ball indices
 // start at index 0. This returns 0, or -1 if you passed it invalid
 // parameters.
 func (joystick *Joystick) GetBall(ball int, dx, dy *int) int {
-	return int(C.SDL_JoystickGetBall(joystick.cJoystick, C.int(ball), (*C.int)(cast(dx)), (*C.int)(cast(dy))))
+	lockGlobal()
+	result := int(C.SDL_JoystickGetBall(joystick.cJoystick, C.int(ball), (*C.int)(cast(dx)), (*C.int)(cast(dy))))
+	unlockGlobal()
+	return result
 }
 
 // Get the current state of an axis control on a joystick. The axis
 // indices start at index 0. The state is a value ranging from -32768
 // to 32767.
 func (joystick *Joystick) GetAxis(axis int) int16 {
-	return int16(C.SDL_JoystickGetAxis(joystick.cJoystick, C.int(axis)))
+	lockGlobal()
+	result := int16(C.SDL_JoystickGetAxis(joystick.cJoystick, C.int(axis)))
+	unlockGlobal()
+	return result
+}
+
+// The state of all controls of a joystick at one point in time.
+type JoystickState struct {
+	Axes    []int16
+	Buttons []bool
+	Hats    []uint8
+	Balls   [][2]int // Relative motion since the previous read (dx, dy)
+}
+
+// Reads the state of all axes, buttons, hats and balls of the joystick at once.
+//
+// The state is read while holding the global mutex, so an event poll
+// can't update the joystick halfway through. If joystick events are disabled
+// (see JoystickEventState), call JoystickUpdate before taking the snapshot.
+// The state of a closed joystick is empty.
+func (joystick *Joystick) Snapshot() JoystickState {
+	lockGlobal()
+
+	j := joystick.cJoystick
+	if j == nil {
+		unlockGlobal()
+		SetError("Joystick hasn't been opened yet")
+		return JoystickState{}
+	}
+
+	state := JoystickState{
+		Axes:    make([]int16, int(C.SDL_JoystickNumAxes(j))),
+		Buttons: make([]bool, int(C.SDL_JoystickNumButtons(j))),
+		Hats:    make([]uint8, int(C.SDL_JoystickNumHats(j))),
+		Balls:   make([][2]int, int(C.SDL_JoystickNumBalls(j))),
+	}
+
+	for i := range state.Axes {
+		state.Axes[i] = int16(C.SDL_JoystickGetAxis(j, C.int(i)))
+	}
+	for i := range state.Buttons {
+		state.Buttons[i] = C.SDL_JoystickGetButton(j, C.int(i)) != 0
+	}
+	for i := range state.Hats {
+		state.Hats[i] = uint8(C.SDL_JoystickGetHat(j, C.int(i)))
+	}
+	for i := range state.Balls {
+		var dx, dy C.int
+		C.SDL_JoystickGetBall(j, C.int(i), &dx, &dy)
+		state.Balls[i] = [2]int{int(dx), int(dy)}
+	}
+
+	unlockGlobal()
+
+	return state
+}
+
+// Returns the battery level of the joystick, one of the JOYSTICK_POWER_* constants.
+//
+// SDL 1.2 has no way to query it, so this always returns JOYSTICK_POWER_UNKNOWN.
+// The method exists so that code warning about low batteries can already be
+// written, and the constants have the values of SDL 2, which does support it.
+func (joystick *Joystick) PowerLevel() int {
+	return JOYSTICK_POWER_UNKNOWN
 }
 
 // ====
@@ -948,9 +2471,9 @@ func (joystick *Joystick) GetAxis(axis int) int16 {
 
 // Gets the number of milliseconds since the SDL library initialization.
 func GetTicks() uint32 {
-	GlobalMutex.Lock()
+	lockGlobal()
 	t := uint32(C.SDL_GetTicks())
-	GlobalMutex.Unlock()
+	unlockGlobal()
 	return t
 }
 
@@ -958,3 +2481,42 @@ func GetTicks() uint32 {
 func Delay(ms uint32) {
 	time.Sleep(time.Duration(ms) * time.Millisecond)
 }
+
+// ======
+// Endian
+// ======
+
+// Returns the byte order of the machine, either LIL_ENDIAN or BIG_ENDIAN.
+func BYTEORDER() int {
+	return C.SDL_BYTEORDER
+}
+
+// Swaps the bytes of a 16-bit value.
+func Swap16(x uint16) uint16 {
+	return uint16(C.SDL_Swap16(C.Uint16(x)))
+}
+
+// Swaps the bytes of a 32-bit value.
+func Swap32(x uint32) uint32 {
+	return uint32(C.SDL_Swap32(C.Uint32(x)))
+}
+
+// Converts a 16-bit value between little-endian and the native byte order.
+func SwapLE16(x uint16) uint16 {
+	return uint16(C.__SDL_SwapLE16(C.Uint16(x)))
+}
+
+// Converts a 32-bit value between little-endian and the native byte order.
+func SwapLE32(x uint32) uint32 {
+	return uint32(C.__SDL_SwapLE32(C.Uint32(x)))
+}
+
+// Converts a 16-bit value between big-endian and the native byte order.
+func SwapBE16(x uint16) uint16 {
+	return uint16(C.__SDL_SwapBE16(C.Uint16(x)))
+}
+
+// Converts a 32-bit value between big-endian and the native byte order.
+func SwapBE32(x uint32) uint32 {
+	return uint32(C.__SDL_SwapBE32(C.Uint32(x)))
+}
@@ -0,0 +1,107 @@
+package sdl
+
+import "testing"
+
+// Initializes SDL with the dummy drivers for the duration of the test,
+// or skips the test if that isn't possible.
+func initHeadless(t *testing.T) {
+	if err := InitHeadless(INIT_VIDEO); err != nil {
+		t.Skip("no dummy video driver:", err)
+	}
+	t.Cleanup(Quit)
+}
+
+func TestClone(t *testing.T) {
+	initHeadless(t)
+
+	src := CreateSurface(SWSURFACE, 4, 3, PixelFormatRGBA8888)
+	if src == nil {
+		t.Fatal(GetError())
+	}
+	defer src.Free()
+
+	src.Lock()
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			src.setPixel(x, y, src.MapRGBA(uint8(x*60), uint8(y*100), 7, 255))
+		}
+	}
+	src.Unlock()
+	src.SetColorKey(SRCCOLORKEY, src.MapRGBA(0, 0, 7, 255))
+
+	clone := src.Clone()
+	if clone == nil {
+		t.Fatal(GetError())
+	}
+	defer clone.Free()
+
+	if !clone.Format.Equal(src.Format) {
+		t.Errorf("clone has format %v, want %v", clone.Format, src.Format)
+	}
+	if clone.Flags&SRCCOLORKEY == 0 || clone.Format.Colorkey != src.Format.Colorkey {
+		t.Errorf("colorkey of the clone wasn't copied")
+	}
+	if !clone.Equal(src) {
+		t.Fatalf("clone has other pixels than the source")
+	}
+
+	clone.FillRect(nil, clone.MapRGBA(255, 255, 255, 255))
+
+	src.Lock()
+	r, g, b, a := src.GetRGBA(src.getPixel(3, 2))
+	src.Unlock()
+	if r != 180 || g != 200 || b != 7 || a != 255 {
+		t.Errorf("source pixel changed to (%d, %d, %d, %d) by filling the clone", r, g, b, a)
+	}
+}
+
+func TestWMCaption(t *testing.T) {
+	initHeadless(t)
+
+	WM_SetCaption("foo", "bar")
+	if title, icon := WM_GetCaption(); title != "foo" || icon != "bar" {
+		t.Errorf("got caption (%q, %q), want (\"foo\", \"bar\")", title, icon)
+	}
+
+	// Setting the caption again frees the strings returned before
+	WM_SetCaption("a longer title", "")
+	if title, icon := WM_GetCaption(); title != "a longer title" || icon != "" {
+		t.Errorf("got caption (%q, %q), want (\"a longer title\", \"\")", title, icon)
+	}
+}
+
+func TestJoystickNameOutOfRange(t *testing.T) {
+	initHeadless(t)
+	if InitSubSystem(INIT_JOYSTICK) != 0 {
+		t.Skip("no joystick support:", GetError())
+	}
+
+	for _, index := range []int{-1, NumJoysticks(), NumJoysticks() + 1} {
+		if name, ok := JoystickName(index); ok || name != "" {
+			t.Errorf("JoystickName(%d) = (%q, %v), want (\"\", false)", index, name, ok)
+		}
+	}
+}
+
+func BenchmarkLockGlobal(b *testing.B) {
+	if singleThreaded() {
+		b.Skip("single-threaded mode is already on")
+	}
+
+	for i := 0; i < b.N; i++ {
+		lockGlobal()
+		unlockGlobal()
+	}
+}
+
+func BenchmarkLockGlobalSingleThreaded(b *testing.B) {
+	if !singleThreaded() {
+		SetSingleThreaded(true)
+		defer SetSingleThreaded(false)
+	}
+
+	for i := 0; i < b.N; i++ {
+		lockGlobal()
+		unlockGlobal()
+	}
+}
@@ -0,0 +1,41 @@
+package sdl
+
+// Releases everything the package knows about and shuts down SDL, in an order
+// that is safe: the surfaces queued by DeferFree are freed, then the joysticks
+// opened by JoystickOpen are closed, then the surfaces counted by
+// SurfaceMemoryStats and ExternalSurfaceMemoryStats that weren't freed yet
+// are freed (together with the copies they own, like the results of
+// DisplayFormatCached and GLPixels), and finally Quit is called, which also
+// frees the screen surface.
+// Use it instead of Quit at the end of the program.
+//
+// Surfaces and joysticks must not be used after Shutdown; freeing or closing
+// them again does nothing. Surfaces created by other packages, like ttf,
+// aren't known here and have to be freed before.
+func Shutdown() {
+	ProcessFrees()
+
+	lockGlobal()
+	joysticks := make([]*Joystick, 0, len(openJoysticks))
+	for j := range openJoysticks {
+		joysticks = append(joysticks, j)
+	}
+	unlockGlobal()
+
+	for _, j := range joysticks {
+		j.Close()
+	}
+
+	tracking.Lock()
+	surfaces := make([]*Surface, 0, len(tracking.live))
+	for s := range tracking.live {
+		surfaces = append(surfaces, s)
+	}
+	tracking.Unlock()
+
+	for _, s := range surfaces {
+		s.Free()
+	}
+
+	Quit()
+}
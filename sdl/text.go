@@ -0,0 +1,36 @@
+package sdl
+
+import "errors"
+
+// A font that can render text to a surface. *ttf.Font implements it,
+// the interface keeps this package independent of package "sdl/ttf".
+type TTFFont interface {
+	// Renders UTF-8 text in the given color to a new surface,
+	// or returns nil on error.
+	RenderUTF8_Blended(text string, color Color) *Surface
+}
+
+// Renders text with the font and blits it to the surface, with the top left
+// corner of the text at (x, y). The rendered surface is freed afterwards.
+//
+// Rendering every frame is slow, for text that rarely changes keep
+// the rendered surface instead.
+func (dst *Surface) BlitText(font TTFFont, text string, x, y int, color Color) error {
+	if text == "" {
+		// SDL_ttf fails on empty text, but there is nothing to draw anyway
+		return nil
+	}
+
+	rendered := font.RenderUTF8_Blended(text, color)
+	if rendered == nil {
+		return errors.New(GetError())
+	}
+
+	status := dst.Blit(&Rect{X: int16(x), Y: int16(y)}, rendered, nil)
+	rendered.Free()
+
+	if status != 0 {
+		return errors.New(GetError())
+	}
+	return nil
+}
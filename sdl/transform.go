@@ -0,0 +1,156 @@
+package sdl
+
+// Creates an empty surface like s, with the given size, the same pixel format
+// and the same colorkey and alpha settings.
+func (s *Surface) createLike(w, h int) *Surface {
+	dst := s.CreateCompatible(w, h)
+	if dst == nil {
+		return nil
+	}
+
+	if s.Flags&SRCCOLORKEY != 0 {
+		dst.SetColorKey(SRCCOLORKEY, s.Format.Colorkey)
+	}
+	if s.Flags&SRCALPHA != 0 {
+		dst.SetAlpha(SRCALPHA, s.Format.Alpha)
+	} else {
+		dst.SetAlpha(0, 0xff)
+	}
+
+	return dst
+}
+
+// Returns a copy of the surface scaled to twice its size with the Scale2x
+// (also known as EPX) algorithm, which keeps the edges of pixel art sharp
+// instead of making them blocky or blurry. Returns nil on error.
+//
+// Pixels are compared for exact equality, so the algorithm works best on
+// images with few colors.
+func (s *Surface) Scale2x() *Surface {
+	w, h := int(s.W), int(s.H)
+
+	dst := s.createLike(2*w, 2*h)
+	if dst == nil {
+		return nil
+	}
+
+	if s.Lock() != 0 {
+		dst.Free()
+		return nil
+	}
+	if dst.Lock() != 0 {
+		s.Unlock()
+		dst.Free()
+		return nil
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := s.getPixel(x, y)
+
+			// The neighbors, the pixel itself outside the surface
+			a, b, c, d := p, p, p, p
+			if y > 0 {
+				a = s.getPixel(x, y-1)
+			}
+			if x < w-1 {
+				b = s.getPixel(x+1, y)
+			}
+			if x > 0 {
+				c = s.getPixel(x-1, y)
+			}
+			if y < h-1 {
+				d = s.getPixel(x, y+1)
+			}
+
+			e0, e1, e2, e3 := p, p, p, p
+			if c == a && c != d && a != b {
+				e0 = a
+			}
+			if a == b && a != c && b != d {
+				e1 = b
+			}
+			if d == c && d != b && c != a {
+				e2 = c
+			}
+			if b == d && b != a && d != c {
+				e3 = d
+			}
+
+			dst.setPixel(2*x, 2*y, e0)
+			dst.setPixel(2*x+1, 2*y, e1)
+			dst.setPixel(2*x, 2*y+1, e2)
+			dst.setPixel(2*x+1, 2*y+1, e3)
+		}
+	}
+
+	dst.Unlock()
+	s.Unlock()
+
+	return dst
+}
+
+// Returns a copy of the surface rotated by a multiple of 90 degrees clockwise.
+// The pixels are only moved, so unlike rotozoomSurface of SDL_gfx
+// the rotation is exact.
+func (s *Surface) rotate(quarters int) *Surface {
+	w, h := int(s.W), int(s.H)
+
+	dw, dh := w, h
+	if quarters%2 == 1 {
+		dw, dh = h, w
+	}
+
+	dst := s.createLike(dw, dh)
+	if dst == nil {
+		return nil
+	}
+
+	if s.Lock() != 0 {
+		dst.Free()
+		return nil
+	}
+	if dst.Lock() != 0 {
+		s.Unlock()
+		dst.Free()
+		return nil
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := x, y
+			switch quarters {
+			case 1:
+				dx, dy = h-1-y, x
+			case 2:
+				dx, dy = w-1-x, h-1-y
+			case 3:
+				dx, dy = y, w-1-x
+			}
+			dst.setPixel(dx, dy, s.getPixel(x, y))
+		}
+	}
+
+	dst.Unlock()
+	s.Unlock()
+
+	return dst
+}
+
+// Returns a copy of the surface rotated by 90 degrees clockwise,
+// without any loss of quality. Returns nil on error.
+func (s *Surface) Rotate90() *Surface {
+	return s.rotate(1)
+}
+
+// Returns a copy of the surface rotated by 180 degrees,
+// without any loss of quality. Returns nil on error.
+func (s *Surface) Rotate180() *Surface {
+	return s.rotate(2)
+}
+
+// Returns a copy of the surface rotated by 270 degrees clockwise
+// (90 degrees counterclockwise), without any loss of quality. Returns nil on error.
+func (s *Surface) Rotate270() *Surface {
+	return s.rotate(3)
+}
@@ -0,0 +1,59 @@
+package sdl
+
+import "testing"
+
+func TestScale2x(t *testing.T) {
+	initHeadless(t)
+
+	// A diagonal line, which Scale2x smooths into a thicker diagonal
+	// instead of a staircase of 2x2 blocks
+	in := [][]int{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	}
+	want := [][]int{
+		{1, 1, 0, 0, 0, 0},
+		{1, 0, 1, 0, 0, 0},
+		{0, 1, 1, 1, 0, 0},
+		{0, 0, 1, 1, 1, 0},
+		{0, 0, 0, 1, 0, 1},
+		{0, 0, 0, 0, 1, 1},
+	}
+
+	src := CreateSurface(SWSURFACE, 3, 3, PixelFormatRGBA8888)
+	if src == nil {
+		t.Fatal(GetError())
+	}
+	defer src.Free()
+
+	colors := []uint32{src.MapRGBA(0, 0, 0, 255), src.MapRGBA(255, 255, 255, 255)}
+
+	src.Lock()
+	for y, row := range in {
+		for x, c := range row {
+			src.setPixel(x, y, colors[c])
+		}
+	}
+	src.Unlock()
+
+	dst := src.Scale2x()
+	if dst == nil {
+		t.Fatal(GetError())
+	}
+	defer dst.Free()
+
+	if dst.W != 6 || dst.H != 6 {
+		t.Fatalf("got a %dx%d surface, want 6x6", dst.W, dst.H)
+	}
+
+	dst.Lock()
+	for y, row := range want {
+		for x, c := range row {
+			if got := dst.getPixel(x, y); got != colors[c] {
+				t.Errorf("pixel (%d, %d) is %#x, want %#x", x, y, got, colors[c])
+			}
+		}
+	}
+	dst.Unlock()
+}
@@ -0,0 +1,31 @@
+package sdl
+
+import "errors"
+
+// Calls the platform specific code to fill info, see GetWMInfo.
+// Returns false, with the SDL error set, if the information isn't available.
+func getWMInfo(info *WMInfo) bool {
+	lockGlobal()
+	ok := fillWMInfo(info)
+	unlockGlobal()
+	return ok
+}
+
+// Gets the native handles of the window created by SetVideoMode,
+// for example to embed it in another toolkit or to pass it to a platform API.
+// The fields of WMInfo depend on the platform:
+//
+//	X11 (Linux and BSD): Display (a Display*), Window (the X11 Window SDL
+//	  draws into), FSWindow (the window used in fullscreen mode) and
+//	  WMWindow (the window managed by the window manager, the parent of Window)
+//	Windows: Window (the HWND) and GLContext (the HGLRC, with OPENGL)
+//	Other platforms: none, GetWMInfo always returns an error
+//
+// Returns an error if there is no window or its handles are unavailable.
+func GetWMInfo() (WMInfo, error) {
+	var info WMInfo
+	if !getWMInfo(&info) {
+		return info, errors.New(GetError())
+	}
+	return info, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !freebsd && !netbsd && !openbsd && !dragonfly && !windows
+// +build !linux,!freebsd,!netbsd,!openbsd,!dragonfly,!windows
+
+package sdl
+
+// Native handles of the window, see GetWMInfo.
+// SDL 1.2 exposes none on this platform.
+type WMInfo struct{}
+
+func fillWMInfo(info *WMInfo) bool {
+	SetError("GetWMInfo: not supported on this platform")
+	return false
+}
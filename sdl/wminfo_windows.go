@@ -0,0 +1,36 @@
+package sdl
+
+// #include <SDL.h>
+// #include <SDL_syswm.h>
+//
+// static int __SDL_GetWMInfo_Windows(void **window, void **glcontext) {
+// 	SDL_SysWMinfo info;
+// 	SDL_VERSION(&info.version);
+// 	if (SDL_GetWMInfo(&info) != 1) {
+// 		return 0;
+// 	}
+// 	*window = info.window;
+// 	*glcontext = info.hglrc;
+// 	return 1;
+// }
+import "C"
+
+import "unsafe"
+
+// Native handles of the window on Windows, see GetWMInfo.
+type WMInfo struct {
+	Window    uintptr // The HWND of the window
+	GLContext uintptr // The HGLRC of the OpenGL context, with OPENGL
+}
+
+func fillWMInfo(info *WMInfo) bool {
+	var window, glcontext unsafe.Pointer
+
+	if C.__SDL_GetWMInfo_Windows(&window, &glcontext) == 0 {
+		return false
+	}
+
+	info.Window = uintptr(window)
+	info.GLContext = uintptr(glcontext)
+	return true
+}
@@ -0,0 +1,55 @@
+//go:build linux || freebsd || netbsd || openbsd || dragonfly
+// +build linux freebsd netbsd openbsd dragonfly
+
+package sdl
+
+// #include <SDL.h>
+// #include <SDL_syswm.h>
+//
+// static int __SDL_GetWMInfo_X11(void **display, unsigned long *window, unsigned long *fswindow, unsigned long *wmwindow) {
+// #if defined(SDL_VIDEO_DRIVER_X11)
+// 	SDL_SysWMinfo info;
+// 	SDL_VERSION(&info.version);
+// 	if (SDL_GetWMInfo(&info) != 1) {
+// 		return 0;
+// 	}
+// 	if (info.subsystem != SDL_SYSWM_X11) {
+// 		SDL_SetError("GetWMInfo: the video driver isn't X11");
+// 		return 0;
+// 	}
+// 	*display = info.info.x11.display;
+// 	*window = info.info.x11.window;
+// 	*fswindow = info.info.x11.fswindow;
+// 	*wmwindow = info.info.x11.wmwindow;
+// 	return 1;
+// #else
+// 	SDL_SetError("GetWMInfo: SDL was built without X11 support");
+// 	return 0;
+// #endif
+// }
+import "C"
+
+import "unsafe"
+
+// Native handles of the window on X11, see GetWMInfo.
+type WMInfo struct {
+	Display  uintptr // The X11 Display*
+	Window   uintptr // The X11 Window SDL draws into
+	FSWindow uintptr // The X11 Window used in fullscreen mode
+	WMWindow uintptr // The X11 Window managed by the window manager
+}
+
+func fillWMInfo(info *WMInfo) bool {
+	var display unsafe.Pointer
+	var window, fswindow, wmwindow C.ulong
+
+	if C.__SDL_GetWMInfo_X11(&display, &window, &fswindow, &wmwindow) == 0 {
+		return false
+	}
+
+	info.Display = uintptr(display)
+	info.Window = uintptr(window)
+	info.FSWindow = uintptr(fswindow)
+	info.WMWindow = uintptr(wmwindow)
+	return true
+}
@@ -226,6 +226,13 @@ func RenderUTF8_Blended(font *Font, text string, color sdl.Color) *sdl.Surface {
 	return wrap(surface)
 }
 
+// Renders UTF-8 text in the specified color and returns an SDL surface,
+// like func RenderUTF8_Blended. Makes Font implement sdl.TTFFont,
+// for use with Surface.BlitText.
+func (f *Font) RenderUTF8_Blended(text string, color sdl.Color) *sdl.Surface {
+	return RenderUTF8_Blended(f, text, color)
+}
+
 // Returns the rendering style of the font.
 func (f *Font) GetStyle() int {
 	f.mutex.RLock()